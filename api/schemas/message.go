@@ -1,13 +1,30 @@
 package schemas
 
-import "github.com/Restyx/golang-reviews-service/internal/model"
+import "encoding/json"
 
 type Message struct {
-	Pattern string       `json:"pattern"`
-	Data    model.Review `json:"data"`
+	Pattern string          `json:"pattern"`
+	Data    json.RawMessage `json:"data"`
+	ID      string          `json:"id,omitempty"`
+}
+
+type MessageReply struct {
+	ID         string          `json:"id,omitempty"`
+	Response   json.RawMessage `json:"response,omitempty"`
+	Err        json.RawMessage `json:"err,omitempty"`
+	IsDisposed bool            `json:"isDisposed"`
 }
 
 type ErrorResponse struct {
-	Code    int    `json:"code"`
+	Code    int          `json:"code"`
+	Error   string       `json:"error"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
 	Message string `json:"message"`
 }
@@ -0,0 +1,114 @@
+package schemas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store"
+	"github.com/Restyx/golang-reviews-service/internal/transport"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	ErrorTypeNotFound          = "not_found"
+	ErrorTypeFieldMissing      = "field_missing"
+	ErrorTypeValidation        = "validation_failed"
+	ErrorTypeUnknownAspect     = "unknown_aspect"
+	ErrorTypeInvalidCursor     = "invalid_cursor"
+	ErrorTypeInvalidBody       = "invalid_body"
+	ErrorTypeBadRequest        = "bad_request"
+	ErrorTypeUnauthorized      = "unauthorized"
+	ErrorTypeInvalidTransition = "invalid_transition"
+	ErrorTypeTimeout           = "timeout"
+	ErrorTypeInternal          = "internal"
+)
+
+func StatusCode(inputError error) int {
+	statusCode, _ := classify(inputError)
+	return statusCode
+}
+
+func ErrorType(inputError error) string {
+	_, errorType := classify(inputError)
+	return errorType
+}
+
+func NewErrorResponse(inputError error) *ErrorResponse {
+	statusCode, errorType := classify(inputError)
+
+	response := &ErrorResponse{
+		Code:    statusCode,
+		Error:   errorType,
+		Message: inputError.Error(),
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(inputError, &validationErrors) {
+		response.Message = "validation failed"
+		for _, fieldError := range validationErrors {
+			response.Details = append(response.Details, newFieldError(fieldError))
+		}
+	}
+
+	return response
+}
+
+func newFieldError(fieldError validator.FieldError) FieldError {
+	field := fieldError.Namespace()
+	if _, name, ok := strings.Cut(field, "."); ok {
+		field = name
+	}
+
+	message := fmt.Sprintf("%s failed on the '%s' rule", field, fieldError.Tag())
+	if fieldError.Param() != "" {
+		message = fmt.Sprintf("%s failed on the '%s=%s' rule", field, fieldError.Tag(), fieldError.Param())
+	}
+
+	return FieldError{
+		Field:   field,
+		Rule:    fieldError.Tag(),
+		Param:   fieldError.Param(),
+		Message: message,
+	}
+}
+
+func classify(inputError error) (int, string) {
+	var (
+		validationErrors validator.ValidationErrors
+		syntaxError      *json.SyntaxError
+		typeError        *json.UnmarshalTypeError
+		notFound         *store.RecordNotFound
+		fieldMissing     *store.RequiredFieldMissing
+		unknownAspect    *model.UnknownAspect
+		transition       *model.InvalidTransition
+	)
+
+	switch {
+	case inputError == nil:
+		return 200, ""
+	case errors.As(inputError, &notFound):
+		return 404, ErrorTypeNotFound
+	case errors.As(inputError, &fieldMissing):
+		return 400, ErrorTypeFieldMissing
+	case errors.As(inputError, &validationErrors):
+		return 400, ErrorTypeValidation
+	case errors.As(inputError, &unknownAspect):
+		return 400, ErrorTypeUnknownAspect
+	case errors.Is(inputError, model.ErrInvalidCursor):
+		return 400, ErrorTypeInvalidCursor
+	case errors.As(inputError, &syntaxError), errors.As(inputError, &typeError):
+		return 400, ErrorTypeInvalidBody
+	case errors.Is(inputError, transport.ErrUnauthorized):
+		return 401, ErrorTypeUnauthorized
+	case errors.As(inputError, &transition):
+		return 409, ErrorTypeInvalidTransition
+	case errors.Is(inputError, context.DeadlineExceeded):
+		return 504, ErrorTypeTimeout
+	default:
+		return 500, ErrorTypeInternal
+	}
+}
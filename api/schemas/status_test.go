@@ -0,0 +1,96 @@
+package schemas_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Restyx/golang-reviews-service/api/schemas"
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusCode(t *testing.T) {
+	testcases := []struct {
+		name         string
+		err          error
+		expectedCode int
+		expectedType string
+	}{
+		{
+			name:         "nil",
+			err:          nil,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "not found",
+			err:          store.NewRecordNotFound("1"),
+			expectedCode: http.StatusNotFound,
+			expectedType: schemas.ErrorTypeNotFound,
+		},
+		{
+			name:         "validation",
+			err:          (&model.Review{Author: "invalid"}).Validate(),
+			expectedCode: http.StatusBadRequest,
+			expectedType: schemas.ErrorTypeValidation,
+		},
+		{
+			name:         "invalid cursor",
+			err:          model.ErrInvalidCursor,
+			expectedCode: http.StatusBadRequest,
+			expectedType: schemas.ErrorTypeInvalidCursor,
+		},
+		{
+			name:         "invalid transition",
+			err:          model.NewInvalidTransition(model.StatusRejected, model.StatusFlagged),
+			expectedCode: http.StatusConflict,
+			expectedType: schemas.ErrorTypeInvalidTransition,
+		},
+		{
+			name:         "timeout",
+			err:          fmt.Errorf("query failed: %w", context.DeadlineExceeded),
+			expectedCode: http.StatusGatewayTimeout,
+			expectedType: schemas.ErrorTypeTimeout,
+		},
+		{
+			name:         "unknown",
+			err:          errors.New("unknown"),
+			expectedCode: http.StatusInternalServerError,
+			expectedType: schemas.ErrorTypeInternal,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			assert.Equal(t, testcase.expectedCode, schemas.StatusCode(testcase.err))
+			assert.Equal(t, testcase.expectedType, schemas.ErrorType(testcase.err))
+		})
+	}
+}
+
+func TestNewErrorResponse(t *testing.T) {
+	err := (&model.Review{Author: "invalid", Rating: 11}).Validate()
+
+	response := schemas.NewErrorResponse(err)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, schemas.ErrorTypeValidation, response.Error)
+	assert.Contains(t, response.Details, schemas.FieldError{
+		Field:   "author",
+		Rule:    "email",
+		Message: "author failed on the 'email' rule",
+	})
+	assert.Contains(t, response.Details, schemas.FieldError{
+		Field:   "rating",
+		Rule:    "lte",
+		Param:   "10",
+		Message: "rating failed on the 'lte=10' rule",
+	})
+
+	response = schemas.NewErrorResponse(store.NewRecordNotFound("7"))
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Equal(t, "record 7 not found", response.Message)
+	assert.Empty(t, response.Details)
+}
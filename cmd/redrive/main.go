@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Restyx/golang-reviews-service/internal/messagehandler"
+	"github.com/Restyx/golang-reviews-service/internal/rabbitmq"
+)
+
+var (
+	configPath string
+	limit      int
+)
+
+func init() {
+	flag.StringVar(&configPath, "config-path", "configs/reviews.toml", "path to config file")
+	flag.IntVar(&limit, "limit", 0, "maximum number of parked messages to re-drive (0 for all)")
+}
+
+func failOnError(err error, msg string) {
+	if err != nil {
+		log.Fatalf("%s: %s", msg, err)
+	}
+}
+
+func main() {
+	flag.Parse()
+	config := messagehandler.NewConfig()
+	_, err := toml.DecodeFile(configPath, config)
+	failOnError(err, "failed to initialize config file")
+
+	rmq := rabbitmq.New()
+	err = rmq.Connect(config.RmqUser, config.RmqPassword, config.RmqHost, config.RmqPort)
+	failOnError(err, "failed to connect to RabbitMQ")
+	defer rmq.Close()
+
+	count, err := rmq.Redrive(limit)
+	failOnError(err, "failed to re-drive parked messages")
+
+	log.Printf("re-drove %d parked messages", count)
+}
@@ -2,118 +2,263 @@ package main_test
 
 import (
 	"encoding/json"
+	"net/http"
 	"testing"
 
 	"github.com/Restyx/golang-reviews-service/internal/messagehandler"
 	"github.com/Restyx/golang-reviews-service/internal/model"
 	"github.com/Restyx/golang-reviews-service/internal/rabbitmq"
+	"github.com/Restyx/golang-reviews-service/internal/store/testingstorage"
+	"github.com/Restyx/golang-reviews-service/internal/transport"
+	"github.com/Restyx/golang-reviews-service/internal/transport/inprocess"
 	"github.com/rabbitmq/amqp091-go"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestMain_Create(t *testing.T) {
-	rmq := prepareTest(t)
-	defer rmq.Close()
+var inProcessPatterns = []string{"reviews-create", "reviews-update", "reviews-delete", "reviews-get-one", "reviews-approve"}
 
-	queue, err := rmq.Channel.QueueDeclare("reviews-create", false, false, false, false, nil)
-	if err != nil {
-		t.Fatal(err)
-	}
+// bus sends messages to the service. Request returns a nil reply and Publish
+// a nil error when the transport does not report the outcome.
+type bus interface {
+	Request(pattern string, body []byte) (*transport.Reply, error)
+	Publish(pattern string, body []byte) error
+	Close()
+}
 
+var transports = []struct {
+	name      string
+	prepare   func(t *testing.T) (bus, []int)
+	rejection error
+}{
+	{
+		name:      "inprocess",
+		prepare:   prepareInProcessTest,
+		rejection: inprocess.ErrRejected,
+	},
+	{
+		name:    "amqp",
+		prepare: prepareTest,
+	},
+}
+
+func TestMain_Create(t *testing.T) {
 	testTable := []struct {
 		name         string
 		inputReview  *model.Review
 		expectedCode int32
 	}{
 		{
-			name:        "valid",
-			inputReview: model.TestReview(t),
+			name:         "valid",
+			inputReview:  model.TestReview(t),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "invalid",
+			inputReview: &model.Review{
+				Author: "not an email",
+				Rating: 5,
+			},
+			expectedCode: http.StatusBadRequest,
 		},
 	}
 
-	for _, testcase := range testTable {
-		t.Run(testcase.name, func(t *testing.T) {
-			body, err := json.Marshal(testcase.inputReview)
-			assert.NoError(t, err)
+	for _, tr := range transports {
+		t.Run(tr.name, func(t *testing.T) {
+			b, _ := tr.prepare(t)
+			defer b.Close()
 
-			err = publishEvent(t, rmq, queue, body)
-			assert.NoError(t, err)
-		})
+			for _, testcase := range testTable {
+				t.Run(testcase.name, func(t *testing.T) {
+					body, err := json.Marshal(testcase.inputReview)
+					assert.NoError(t, err)
 
+					reply, err := b.Request("reviews-create", body)
+					assert.NoError(t, err)
+					if reply != nil {
+						assert.Equal(t, testcase.expectedCode, reply.Code)
+					}
+				})
+			}
+		})
 	}
 }
 
 func TestMain_Update(t *testing.T) {
-	rmq := prepareTest(t)
-	defer rmq.Close()
+	for _, tr := range transports {
+		t.Run(tr.name, func(t *testing.T) {
+			b, reviews := tr.prepare(t)
+			defer b.Close()
 
-	queue, err := rmq.Channel.QueueDeclare("reviews-update", false, false, false, false, nil)
-	if err != nil {
-		t.Fatal(err)
-	}
+			testTable := []struct {
+				name         string
+				inputReview  *model.Review
+				expectedCode int32
+			}{
+				{
+					name: "valid",
+					inputReview: &model.Review{
+						ID:          reviews[0],
+						Author:      "updated_mail@example.com",
+						Rating:      5,
+						Title:       "Review Title",
+						Description: "",
+					},
+					expectedCode: http.StatusOK,
+				},
+				{
+					name: "not found",
+					inputReview: &model.Review{
+						ID:     1000,
+						Rating: 5,
+					},
+					expectedCode: http.StatusNotFound,
+				},
+			}
 
-	testTable := []struct {
-		name         string
-		inputReview  *model.Review
-		expectedCode int32
-	}{
-		{
-			name: "valid",
-			inputReview: &model.Review{
-				ID:          57,
-				Author:      "updated_mail@example.com",
-				Rating:      5,
-				Title:       "Review Title",
-				Description: "",
-			},
-		},
-	}
+			for _, testcase := range testTable {
+				t.Run(testcase.name, func(t *testing.T) {
+					body, err := json.Marshal(testcase.inputReview)
+					assert.NoError(t, err)
+
+					reply, err := b.Request("reviews-update", body)
+					assert.NoError(t, err)
+					if reply != nil {
+						assert.Equal(t, testcase.expectedCode, reply.Code)
+					}
+				})
+			}
 
-	for _, testcase := range testTable {
-		t.Run(testcase.name, func(t *testing.T) {
-			body, err := json.Marshal(testcase.inputReview)
+			body, err := json.Marshal(&model.Moderation{ReviewID: reviews[0], Moderator: "moderator@example.com"})
 			assert.NoError(t, err)
+			assert.NoError(t, b.Publish("reviews-approve", body))
 
-			err = publishEvent(t, rmq, queue, body)
+			body, err = encodeId(t, reviews[0])
 			assert.NoError(t, err)
-		})
 
+			reply, err := b.Request("reviews-get-one", body)
+			assert.NoError(t, err)
+			if reply != nil {
+				review := &model.Review{}
+				assert.NoError(t, json.Unmarshal(reply.Body, review))
+				assert.Equal(t, "updated_mail@example.com", review.Author)
+			}
+		})
 	}
 }
 
 func TestMain_Delete(t *testing.T) {
-	rmq := prepareTest(t)
-	defer rmq.Close()
+	for _, tr := range transports {
+		t.Run(tr.name, func(t *testing.T) {
+			b, reviews := tr.prepare(t)
+			defer b.Close()
+
+			testTable := []struct {
+				name        string
+				inputId     int
+				expectError bool
+			}{
+				{
+					name:    "valid",
+					inputId: reviews[1],
+				},
+				{
+					name:        "already deleted",
+					inputId:     reviews[1],
+					expectError: true,
+				},
+			}
+
+			for _, testcase := range testTable {
+				t.Run(testcase.name, func(t *testing.T) {
+					body, err := encodeId(t, testcase.inputId)
+					assert.NoError(t, err)
+
+					err = b.Publish("reviews-delete", body)
+					if testcase.expectError && tr.rejection != nil {
+						assert.ErrorIs(t, err, tr.rejection)
+					} else {
+						assert.NoError(t, err)
+					}
+				})
+			}
+		})
+	}
+}
 
-	queue, err := rmq.Channel.QueueDeclare("reviews-delete", false, false, false, false, nil)
+type inProcessBus struct {
+	*inprocess.Transport
+}
+
+func (b inProcessBus) Request(pattern string, body []byte) (*transport.Reply, error) {
+	return b.Transport.Request(pattern, body)
+}
+
+func (b inProcessBus) Publish(pattern string, body []byte) error {
+	return b.Transport.Publish(pattern, body)
+}
+
+func prepareInProcessTest(t *testing.T) (bus, []int) {
+	t.Helper()
+
+	config := messagehandler.NewConfig()
+	service := messagehandler.NewService(testingstorage.New(), config)
+
+	b := inprocess.New()
+	router := messagehandler.New(service, b)
+
+	messages, err := b.Consume(inProcessPatterns)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	testTable := []struct {
-		name         string
-		inputId      int
-		expectedCode int32
-	}{
-		{
-			name:    "valid",
-			inputId: 58,
-		},
+	go router.HandleMessages(messages)
+
+	reviews := make([]int, 2)
+	for i := range reviews {
+		body, err := json.Marshal(model.TestReview(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		reply, err := b.Request("reviews-create", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		review := &model.Review{}
+		if err := json.Unmarshal(reply.Body, review); err != nil {
+			t.Fatal(err)
+		}
+
+		reviews[i] = review.ID
 	}
 
-	for _, testcase := range testTable {
-		t.Run(testcase.name, func(t *testing.T) {
-			body, err := encodeId(t, testcase.inputId)
-			assert.NoError(t, err)
+	return inProcessBus{b}, reviews
+}
 
-			err = publishEvent(t, rmq, queue, body)
-			assert.NoError(t, err)
-		})
+// amqpBus publishes to a broker consumed by a separately running service, so
+// it cannot observe replies or rejections.
+type amqpBus struct {
+	*rabbitmq.Rabbitmq
+}
 
+func (b amqpBus) Request(pattern string, body []byte) (*transport.Reply, error) {
+	return nil, b.Publish(pattern, body)
+}
+
+func (b amqpBus) Publish(pattern string, body []byte) error {
+	queue, err := b.Channel.QueueDeclare(pattern, false, false, false, false, nil)
+	if err != nil {
+		return err
 	}
+
+	return b.Channel.Publish("reviews", queue.Name, false, false, amqp091.Publishing{
+		ContentType: "application/json",
+		Body:        body,
+	})
 }
 
-func prepareTest(t *testing.T) *rabbitmq.Rabbitmq {
+func prepareTest(t *testing.T) (bus, []int) {
 	t.Helper()
 
 	config := messagehandler.NewConfig()
@@ -130,16 +275,7 @@ func prepareTest(t *testing.T) *rabbitmq.Rabbitmq {
 		t.Fatal(err)
 	}
 
-	return rmq
-}
-
-func publishEvent(t *testing.T, rmq *rabbitmq.Rabbitmq, queue amqp091.Queue, body []byte) error {
-	t.Helper()
-
-	return rmq.Channel.Publish("reviews", queue.Name, false, false, amqp091.Publishing{
-		ContentType: "application/json",
-		Body:        []byte(body),
-	})
+	return amqpBus{rmq}, []int{57, 58}
 }
 
 func encodeId(t *testing.T, id int) ([]byte, error) {
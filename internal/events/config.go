@@ -0,0 +1,25 @@
+package events
+
+import "time"
+
+type Config struct {
+	Enabled          bool          `toml:"enabled"`
+	Exchange         string        `toml:"exchange"`
+	BatchSize        int           `toml:"batch_size"`
+	PollInterval     time.Duration `toml:"poll_interval"`
+	MaxRetryInterval time.Duration `toml:"max_retry_interval"`
+	Retention        time.Duration `toml:"retention"`
+	CleanupInterval  time.Duration `toml:"cleanup_interval"`
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Enabled:          false,
+		Exchange:         "review_events",
+		BatchSize:        100,
+		PollInterval:     time.Second,
+		MaxRetryInterval: 30 * time.Second,
+		Retention:        24 * time.Hour,
+		CleanupInterval:  time.Hour,
+	}
+}
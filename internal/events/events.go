@@ -0,0 +1,95 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store"
+	"github.com/sirupsen/logrus"
+)
+
+type Sink interface {
+	Send(*model.Event) error
+}
+
+type Relay struct {
+	logger *logrus.Logger
+	outbox store.OutboxRepositoryI
+	sink   Sink
+	config *Config
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+func NewRelay(outbox store.OutboxRepositoryI, sink Sink, config *Config) *Relay {
+	return &Relay{
+		logger: logrus.New(),
+		outbox: outbox,
+		sink:   sink,
+		config: config,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+func (r *Relay) Run() {
+	defer close(r.done)
+
+	poll := time.NewTimer(0)
+	defer poll.Stop()
+
+	cleanup := time.NewTicker(r.config.CleanupInterval)
+	defer cleanup.Stop()
+
+	delay := r.config.PollInterval
+
+	for {
+		select {
+		case <-r.stop:
+			if _, err := r.Flush(); err != nil {
+				r.logger.Warnf("failed to flush outbox on shutdown: %s", err)
+			}
+			return
+
+		case <-poll.C:
+			sent, err := r.Flush()
+			switch {
+			case err != nil:
+				r.logger.Warnf("failed to publish outbox events, retrying in %s: %s", delay, err)
+				poll.Reset(delay)
+				delay = min(delay*2, r.config.MaxRetryInterval)
+			case sent == r.config.BatchSize:
+				poll.Reset(0)
+				delay = r.config.PollInterval
+			default:
+				poll.Reset(r.config.PollInterval)
+				delay = r.config.PollInterval
+			}
+
+		case <-cleanup.C:
+			count, err := r.outbox.Cleanup(context.Background(), time.Now().Add(-r.config.Retention))
+			if err != nil {
+				r.logger.Warnf("failed to clean up outbox: %s", err)
+			} else if count > 0 {
+				r.logger.Infof("removed %d delivered outbox events", count)
+			}
+		}
+	}
+}
+
+func (r *Relay) Flush() (int, error) {
+	return r.outbox.Process(context.Background(), r.config.BatchSize, func(message *model.OutboxMessage) error {
+		return r.sink.Send(&message.Event)
+	})
+}
+
+func (r *Relay) Close() {
+	r.once.Do(func() {
+		close(r.stop)
+	})
+
+	<-r.done
+}
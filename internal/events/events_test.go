@@ -0,0 +1,125 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/events"
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store/testingstorage"
+	"github.com/stretchr/testify/assert"
+)
+
+type flakySink struct {
+	mu       sync.Mutex
+	failures map[int]int
+	sent     []*model.Event
+}
+
+func (s *flakySink) Send(event *model.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures[event.ReviewID] > 0 {
+		s.failures[event.ReviewID]--
+		return errors.New("broker unavailable")
+	}
+
+	s.sent = append(s.sent, event)
+
+	return nil
+}
+
+func (s *flakySink) events() []*model.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*model.Event(nil), s.sent...)
+}
+
+func testConfig() *events.Config {
+	config := events.NewConfig()
+	config.PollInterval = time.Millisecond
+	config.MaxRetryInterval = 4 * time.Millisecond
+
+	return config
+}
+
+func prepareStore(t *testing.T) *testingstorage.Store {
+	t.Helper()
+
+	store := testingstorage.New()
+	store.EnableOutbox()
+
+	for i := 0; i < 2; i++ {
+		review := model.TestReview(t)
+		if _, err := store.Review().Create(context.Background(), review); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Review().Update(context.Background(), &model.Review{ID: review.ID, Rating: 9}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return store
+}
+
+func TestRelay_Flush(t *testing.T) {
+	store := prepareStore(t)
+	sink := &flakySink{failures: map[int]int{1: 1}}
+	relay := events.NewRelay(store.Outbox(), sink, testConfig())
+
+	sent, err := relay.Flush()
+	assert.Error(t, err)
+	assert.Equal(t, 2, sent)
+
+	sent, err = relay.Flush()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, sent)
+
+	sent, err = relay.Flush()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	var order []string
+	for _, event := range sink.events() {
+		if event.ReviewID == 1 {
+			order = append(order, event.Type)
+		}
+	}
+	assert.Equal(t, []string{model.EventReviewCreated, model.EventReviewUpdated}, order)
+}
+
+func TestRelay_Run(t *testing.T) {
+	store := prepareStore(t)
+	sink := &flakySink{failures: map[int]int{2: 3}}
+	relay := events.NewRelay(store.Outbox(), sink, testConfig())
+
+	go relay.Run()
+
+	assert.Eventually(t, func() bool {
+		return len(sink.events()) == 4
+	}, time.Second, time.Millisecond)
+
+	relay.Close()
+	relay.Close()
+}
+
+func TestOutbox_Cleanup(t *testing.T) {
+	store := prepareStore(t)
+	relay := events.NewRelay(store.Outbox(), &flakySink{}, testConfig())
+
+	_, err := relay.Flush()
+	assert.NoError(t, err)
+
+	count, err := store.Outbox().Cleanup(context.Background(), time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	count, err = store.Outbox().Cleanup(context.Background(), time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+}
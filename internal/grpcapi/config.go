@@ -0,0 +1,13 @@
+package grpcapi
+
+type Config struct {
+	Enabled bool   `toml:"enabled"`
+	Addr    string `toml:"addr"`
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Enabled: false,
+		Addr:    ":9090",
+	}
+}
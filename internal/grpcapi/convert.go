@@ -0,0 +1,111 @@
+package grpcapi
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/api/reviewspb"
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toRating(field string, value int32) (int8, error) {
+	if value < math.MinInt8 || value > math.MaxInt8 {
+		return 0, status.Error(codes.InvalidArgument, fmt.Sprintf("%s out of range: %d", field, value))
+	}
+
+	return int8(value), nil
+}
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
+
+func reviewFromProto(message *reviewspb.Review) (*model.Review, error) {
+	if message == nil {
+		return nil, status.Error(codes.InvalidArgument, "review is required")
+	}
+
+	rating, err := toRating("rating", message.GetRating())
+	if err != nil {
+		return nil, err
+	}
+
+	review := &model.Review{
+		ID:          int(message.GetId()),
+		Author:      message.GetAuthor(),
+		Rating:      rating,
+		Title:       message.GetTitle(),
+		Description: message.GetDescription(),
+		SubjectType: message.GetSubjectType(),
+		SubjectID:   message.GetSubjectId(),
+	}
+
+	if len(message.GetAspects()) > 0 {
+		review.Aspects = make(map[string]int8, len(message.GetAspects()))
+		for name, value := range message.GetAspects() {
+			if review.Aspects[name], err = toRating("aspect "+name, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return review, nil
+}
+
+func reviewToProto(review *model.Review) *reviewspb.Review {
+	message := &reviewspb.Review{
+		Id:             int64(review.ID),
+		Author:         review.Author,
+		Rating:         int32(review.Rating),
+		Title:          review.Title,
+		Description:    review.Description,
+		SubjectType:    review.SubjectType,
+		SubjectId:      review.SubjectID,
+		Status:         review.Status,
+		HelpfulVotes:   int32(review.HelpfulVotes),
+		UnhelpfulVotes: int32(review.UnhelpfulVotes),
+		CreatedAt:      toTimestamp(review.CreatedAt),
+		UpdatedAt:      toTimestamp(review.UpdatedAt),
+	}
+
+	if len(review.Aspects) > 0 {
+		message.Aspects = make(map[string]int32, len(review.Aspects))
+		for name, value := range review.Aspects {
+			message.Aspects[name] = int32(value)
+		}
+	}
+
+	return message
+}
+
+func queryFromProto(request *reviewspb.ListReviewsRequest) (*model.ReviewQuery, error) {
+	minRating, err := toRating("min_rating", request.GetMinRating())
+	if err != nil {
+		return nil, err
+	}
+
+	maxRating, err := toRating("max_rating", request.GetMaxRating())
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ReviewQuery{
+		Limit:       int(request.GetPageSize()),
+		Cursor:      request.GetPageToken(),
+		SortBy:      request.GetSortBy(),
+		SortOrder:   request.GetSortOrder(),
+		MinRating:   minRating,
+		MaxRating:   maxRating,
+		Author:      request.GetAuthor(),
+		SubjectType: request.GetSubjectType(),
+		SubjectID:   request.GetSubjectId(),
+	}, nil
+}
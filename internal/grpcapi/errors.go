@@ -0,0 +1,42 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Restyx/golang-reviews-service/api/schemas"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorCodes maps the shared error classification onto gRPC codes, so the
+// HTTP, AMQP and gRPC APIs agree on which errors are the caller's fault.
+var errorCodes = map[string]codes.Code{
+	schemas.ErrorTypeNotFound:          codes.NotFound,
+	schemas.ErrorTypeFieldMissing:      codes.InvalidArgument,
+	schemas.ErrorTypeValidation:        codes.InvalidArgument,
+	schemas.ErrorTypeUnknownAspect:     codes.InvalidArgument,
+	schemas.ErrorTypeInvalidCursor:     codes.InvalidArgument,
+	schemas.ErrorTypeInvalidBody:       codes.InvalidArgument,
+	schemas.ErrorTypeBadRequest:        codes.InvalidArgument,
+	schemas.ErrorTypeUnauthorized:      codes.Unauthenticated,
+	schemas.ErrorTypeInvalidTransition: codes.FailedPrecondition,
+	schemas.ErrorTypeTimeout:           codes.DeadlineExceeded,
+}
+
+func toStatus(inputError error) error {
+	if inputError == nil {
+		return nil
+	}
+
+	if errors.Is(inputError, context.Canceled) {
+		return status.Error(codes.Canceled, inputError.Error())
+	}
+
+	code, ok := errorCodes[schemas.ErrorType(inputError)]
+	if !ok {
+		code = codes.Internal
+	}
+
+	return status.Error(code, inputError.Error())
+}
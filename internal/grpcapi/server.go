@@ -0,0 +1,102 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/Restyx/golang-reviews-service/api/reviewspb"
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"google.golang.org/grpc"
+)
+
+type ServiceI interface {
+	Create(context.Context, *model.Review) error
+	Update(context.Context, *model.Review) error
+	Delete(context.Context, int) error
+	ReadOne(context.Context, int) (*model.Review, error)
+	ReadPage(context.Context, *model.ReviewQuery) (*model.ReviewPage, error)
+}
+
+type Server struct {
+	reviewspb.UnimplementedReviewsServiceServer
+	service ServiceI
+}
+
+func New(service ServiceI) *Server {
+	return &Server{
+		service: service,
+	}
+}
+
+func NewServer(service ServiceI, options ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(options...)
+	reviewspb.RegisterReviewsServiceServer(server, New(service))
+
+	return server
+}
+
+func (s *Server) CreateReview(ctx context.Context, request *reviewspb.CreateReviewRequest) (*reviewspb.CreateReviewResponse, error) {
+	review, err := reviewFromProto(request.GetReview())
+	if err != nil {
+		return nil, err
+	}
+	review.ID = 0
+
+	if err := s.service.Create(ctx, review); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &reviewspb.CreateReviewResponse{Review: reviewToProto(review)}, nil
+}
+
+func (s *Server) UpdateReview(ctx context.Context, request *reviewspb.UpdateReviewRequest) (*reviewspb.UpdateReviewResponse, error) {
+	review, err := reviewFromProto(request.GetReview())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.service.Update(ctx, review); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &reviewspb.UpdateReviewResponse{Review: reviewToProto(review)}, nil
+}
+
+func (s *Server) DeleteReview(ctx context.Context, request *reviewspb.DeleteReviewRequest) (*reviewspb.DeleteReviewResponse, error) {
+	if err := s.service.Delete(ctx, int(request.GetId())); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &reviewspb.DeleteReviewResponse{}, nil
+}
+
+func (s *Server) GetReview(ctx context.Context, request *reviewspb.GetReviewRequest) (*reviewspb.GetReviewResponse, error) {
+	review, err := s.service.ReadOne(ctx, int(request.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &reviewspb.GetReviewResponse{Review: reviewToProto(review)}, nil
+}
+
+func (s *Server) ListReviews(ctx context.Context, request *reviewspb.ListReviewsRequest) (*reviewspb.ListReviewsResponse, error) {
+	query, err := queryFromProto(request)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.service.ReadPage(ctx, query)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &reviewspb.ListReviewsResponse{
+		Reviews:       make([]*reviewspb.Review, 0, len(page.Reviews)),
+		NextPageToken: page.NextCursor,
+		TotalSize:     int32(page.Total),
+	}
+	for i := range page.Reviews {
+		response.Reviews = append(response.Reviews, reviewToProto(&page.Reviews[i]))
+	}
+
+	return response, nil
+}
@@ -0,0 +1,174 @@
+package grpcapi_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/Restyx/golang-reviews-service/api/reviewspb"
+	"github.com/Restyx/golang-reviews-service/internal/grpcapi"
+	"github.com/Restyx/golang-reviews-service/internal/messagehandler"
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store/testingstorage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestClient(t *testing.T) (reviewspb.ReviewsServiceClient, messagehandler.ServiceI) {
+	t.Helper()
+
+	service := messagehandler.NewService(testingstorage.New(), messagehandler.NewConfig())
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpcapi.NewServer(service)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return reviewspb.NewReviewsServiceClient(conn), service
+}
+
+func TestServer_CreateReview(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	testTable := []struct {
+		name         string
+		inputReview  *reviewspb.Review
+		expectedCode codes.Code
+	}{
+		{
+			name: "valid",
+			inputReview: &reviewspb.Review{
+				Author:      "example_mail@example.com",
+				Rating:      7,
+				Title:       "Title",
+				Description: "Description of the review",
+				SubjectType: "product",
+				SubjectId:   "sku-42",
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "missing review",
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "rating out of range",
+			inputReview: &reviewspb.Review{
+				Author:      "example_mail@example.com",
+				Rating:      257,
+				Title:       "Title",
+				Description: "Description of the review",
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "unknown aspect",
+			inputReview: &reviewspb.Review{
+				Author:      "example_mail@example.com",
+				Rating:      7,
+				Title:       "Title",
+				Description: "Description of the review",
+				SubjectType: "product",
+				SubjectId:   "sku-42",
+				Aspects:     map[string]int32{"battery_life": 5},
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			response, err := client.CreateReview(context.Background(), &reviewspb.CreateReviewRequest{Review: testcase.inputReview})
+
+			assert.Equal(t, testcase.expectedCode, status.Code(err))
+			if testcase.expectedCode == codes.OK {
+				assert.NotZero(t, response.GetReview().GetId())
+				assert.Equal(t, model.StatusPending, response.GetReview().GetStatus())
+				assert.NotNil(t, response.GetReview().GetCreatedAt())
+			}
+		})
+	}
+}
+
+func TestServer_GetUpdateDeleteReview(t *testing.T) {
+	client, service := newTestClient(t)
+	ctx := context.Background()
+
+	review := model.TestReview(t)
+	assert.NoError(t, service.Create(context.Background(), review))
+
+	_, err := client.GetReview(ctx, &reviewspb.GetReviewRequest{Id: int64(review.ID)})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	assert.NoError(t, service.Approve(context.Background(), &model.Moderation{ReviewID: review.ID, Moderator: "moderator@example.com"}))
+
+	got, err := client.GetReview(ctx, &reviewspb.GetReviewRequest{Id: int64(review.ID)})
+	assert.NoError(t, err)
+	assert.Equal(t, review.Title, got.GetReview().GetTitle())
+
+	_, err = client.GetReview(ctx, &reviewspb.GetReviewRequest{Id: int64(review.ID + 1)})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.GetReview(ctx, &reviewspb.GetReviewRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	updated, err := client.UpdateReview(ctx, &reviewspb.UpdateReviewRequest{Review: &reviewspb.Review{Id: int64(review.ID), Title: "Updated title"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Updated title", updated.GetReview().GetTitle())
+	assert.Equal(t, review.Description, updated.GetReview().GetDescription())
+	assert.Equal(t, model.StatusPending, updated.GetReview().GetStatus())
+
+	_, err = client.UpdateReview(ctx, &reviewspb.UpdateReviewRequest{Review: &reviewspb.Review{Title: "Updated title"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.DeleteReview(ctx, &reviewspb.DeleteReviewRequest{Id: int64(review.ID)})
+	assert.NoError(t, err)
+
+	_, err = client.DeleteReview(ctx, &reviewspb.DeleteReviewRequest{Id: int64(review.ID)})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServer_ListReviews(t *testing.T) {
+	client, service := newTestClient(t)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		review := model.TestReview(t)
+		review.Rating = int8(i + 4)
+		assert.NoError(t, service.Create(context.Background(), review))
+		assert.NoError(t, service.Approve(context.Background(), &model.Moderation{ReviewID: review.ID, Moderator: "moderator@example.com"}))
+	}
+
+	response, err := client.ListReviews(ctx, &reviewspb.ListReviewsRequest{PageSize: 2, SortBy: "rating", SortOrder: "desc"})
+	assert.NoError(t, err)
+	assert.Len(t, response.GetReviews(), 2)
+	assert.Equal(t, int32(6), response.GetReviews()[0].GetRating())
+	assert.Equal(t, int32(3), response.GetTotalSize())
+	assert.NotEmpty(t, response.GetNextPageToken())
+
+	response, err = client.ListReviews(ctx, &reviewspb.ListReviewsRequest{PageSize: 2, SortBy: "rating", SortOrder: "desc", PageToken: response.GetNextPageToken()})
+	assert.NoError(t, err)
+	assert.Len(t, response.GetReviews(), 1)
+	assert.Empty(t, response.GetNextPageToken())
+
+	_, err = client.ListReviews(ctx, &reviewspb.ListReviewsRequest{MinRating: 1000})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.ListReviews(ctx, &reviewspb.ListReviewsRequest{PageToken: "not-a-cursor"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
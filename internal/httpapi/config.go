@@ -0,0 +1,19 @@
+package httpapi
+
+import "time"
+
+type Config struct {
+	Enabled      bool          `toml:"enabled"`
+	Addr         string        `toml:"addr"`
+	ReadTimeout  time.Duration `toml:"read_timeout"`
+	WriteTimeout time.Duration `toml:"write_timeout"`
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Enabled:      false,
+		Addr:         ":8080",
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+}
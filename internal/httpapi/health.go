@@ -0,0 +1,39 @@
+package httpapi
+
+import "net/http"
+
+const (
+	healthStatusUp   = "up"
+	healthStatusDown = "down"
+)
+
+type HealthCheck func() error
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func (s *Server) AddHealthCheck(name string, check HealthCheck) {
+	s.checks[name] = check
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	response := healthResponse{
+		Status: healthStatusUp,
+		Checks: make(map[string]string, len(s.checks)),
+	}
+	statusCode := http.StatusOK
+
+	for name, check := range s.checks {
+		if err := check(); err != nil {
+			response.Checks[name] = err.Error()
+			response.Status = healthStatusDown
+			statusCode = http.StatusServiceUnavailable
+			continue
+		}
+		response.Checks[name] = healthStatusUp
+	}
+
+	s.respond(w, statusCode, response)
+}
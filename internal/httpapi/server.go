@@ -0,0 +1,235 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Restyx/golang-reviews-service/api/schemas"
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store"
+	"github.com/sirupsen/logrus"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+type ServiceI interface {
+	Create(context.Context, *model.Review) error
+	Update(context.Context, *model.Review) error
+	Delete(context.Context, int) error
+	ReadOne(context.Context, int) (*model.Review, error)
+	ReadPage(context.Context, *model.ReviewQuery) (*model.ReviewPage, error)
+}
+
+var errInvalidID = errors.New("invalid review id")
+
+type Server struct {
+	logger  *logrus.Logger
+	service ServiceI
+	router  *http.ServeMux
+	server  *http.Server
+	checks  map[string]HealthCheck
+}
+
+func New(service ServiceI, config *Config) *Server {
+	s := &Server{
+		logger:  logrus.New(),
+		service: service,
+		router:  http.NewServeMux(),
+		checks:  map[string]HealthCheck{},
+	}
+
+	s.router.HandleFunc("GET /health", s.handleHealth)
+	s.router.HandleFunc("GET /reviews", s.handleReadPage)
+	s.router.HandleFunc("POST /reviews", s.handleCreate)
+	s.router.HandleFunc("GET /reviews/{id}", s.handleReadOne)
+	s.router.HandleFunc("PATCH /reviews/{id}", s.handleUpdate)
+	s.router.HandleFunc("DELETE /reviews/{id}", s.handleDelete)
+
+	s.server = &http.Server{
+		Addr:         config.Addr,
+		Handler:      s,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+	}
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.logger.Infof("received request: %s %s", r.Method, r.URL.Path)
+	s.router.ServeHTTP(w, r)
+}
+
+func (s *Server) ListenAndServe() error {
+	return s.server.ListenAndServe()
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) handleReadPage(w http.ResponseWriter, r *http.Request) {
+	query, err := decodeQuery(r.URL.Query())
+	if err != nil {
+		s.error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := s.service.ReadPage(r.Context(), query)
+	if err != nil {
+		s.error(w, schemas.StatusCode(err), err)
+		return
+	}
+
+	s.respond(w, http.StatusOK, page)
+}
+
+func (s *Server) handleReadOne(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		s.error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	review, err := s.service.ReadOne(r.Context(), id)
+	if err != nil {
+		s.error(w, schemas.StatusCode(err), err)
+		return
+	}
+
+	s.respond(w, http.StatusOK, review)
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	review := &model.Review{}
+	if err := json.NewDecoder(r.Body).Decode(review); err != nil {
+		s.error(w, http.StatusBadRequest, err)
+		return
+	}
+	review.ID = 0
+
+	ctx := r.Context()
+	if key := r.Header.Get(idempotencyKeyHeader); key != "" {
+		ctx = store.WithIdempotencyKey(ctx, key)
+	}
+
+	if err := s.service.Create(ctx, review); err != nil {
+		s.error(w, schemas.StatusCode(err), err)
+		return
+	}
+
+	s.respond(w, http.StatusCreated, review)
+}
+
+func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		s.error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	review := &model.Review{}
+	if err := json.NewDecoder(r.Body).Decode(review); err != nil {
+		s.error(w, http.StatusBadRequest, err)
+		return
+	}
+	review.ID = id
+
+	if err := s.service.Update(r.Context(), review); err != nil {
+		s.error(w, schemas.StatusCode(err), err)
+		return
+	}
+
+	s.respond(w, http.StatusOK, review)
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		s.error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := s.service.Delete(r.Context(), id); err != nil {
+		s.error(w, schemas.StatusCode(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) respond(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		s.logger.Errorf("failed to write response: %s", err)
+	}
+}
+
+func (s *Server) error(w http.ResponseWriter, statusCode int, err error) {
+	s.logger.Errorf("request failed with status code %d: %s", statusCode, err)
+
+	response := schemas.NewErrorResponse(err)
+	if response.Code != statusCode {
+		response.Code = statusCode
+		response.Error = schemas.ErrorTypeBadRequest
+	}
+
+	s.respond(w, statusCode, response)
+}
+
+func pathID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		return 0, errInvalidID
+	}
+
+	return id, nil
+}
+
+func decodeQuery(values url.Values) (*model.ReviewQuery, error) {
+	query := &model.ReviewQuery{
+		Cursor:      values.Get("cursor"),
+		SortBy:      values.Get("sort_by"),
+		SortOrder:   values.Get("sort_order"),
+		Author:      values.Get("author"),
+		SubjectType: values.Get("subject_type"),
+		SubjectID:   values.Get("subject_id"),
+	}
+
+	ints := map[string]*int{
+		"limit":  &query.Limit,
+		"offset": &query.Offset,
+	}
+	for key, target := range ints {
+		if value := values.Get(key); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %s", key, value)
+			}
+			*target = parsed
+		}
+	}
+
+	ratings := map[string]*int8{
+		"min_rating": &query.MinRating,
+		"max_rating": &query.MaxRating,
+	}
+	for key, target := range ratings {
+		if value := values.Get(key); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %s", key, value)
+			}
+			*target = int8(parsed)
+		}
+	}
+
+	return query, nil
+}
@@ -0,0 +1,247 @@
+package httpapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/api/schemas"
+	"github.com/Restyx/golang-reviews-service/internal/httpapi"
+	"github.com/Restyx/golang-reviews-service/internal/messagehandler"
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store/testingstorage"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) (*httpapi.Server, messagehandler.ServiceI) {
+	t.Helper()
+
+	service := messagehandler.NewService(testingstorage.New(), messagehandler.NewConfig())
+
+	return httpapi.New(service, httpapi.NewConfig()), service
+}
+
+func serve(t *testing.T, server http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+
+	return recorder
+}
+
+func TestServer_Create(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	testTable := []struct {
+		name         string
+		body         string
+		expectedCode int
+	}{
+		{
+			name:         "valid",
+			body:         `{"author": "example_mail@example.com", "rating": 7, "title": "Title", "description": "Description of the review"}`,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "malformed json",
+			body:         `{"author": `,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid review",
+			body:         `{"author": "invalid", "rating": 7}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			response := serve(t, server, http.MethodPost, "/reviews", testcase.body)
+
+			assert.Equal(t, testcase.expectedCode, response.Code)
+			assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+
+			if testcase.expectedCode == http.StatusCreated {
+				review := &model.Review{}
+				assert.NoError(t, json.Unmarshal(response.Body.Bytes(), review))
+				assert.NotZero(t, review.ID)
+				assert.Equal(t, model.StatusPending, review.Status)
+			} else {
+				errorResponse := &schemas.ErrorResponse{}
+				assert.NoError(t, json.Unmarshal(response.Body.Bytes(), errorResponse))
+				assert.Equal(t, testcase.expectedCode, errorResponse.Code)
+				assert.NotEmpty(t, errorResponse.Message)
+			}
+		})
+	}
+}
+
+func TestServer_CreateIdempotent(t *testing.T) {
+	storage := testingstorage.New()
+	storage.EnableIdempotency(time.Hour)
+	server := httpapi.New(messagehandler.NewService(storage, messagehandler.NewConfig()), httpapi.NewConfig())
+
+	create := func(key, title string) *model.Review {
+		request := httptest.NewRequest(http.MethodPost, "/reviews", strings.NewReader(`{"author": "example_mail@example.com", "rating": 7, "title": "`+title+`", "description": "Description of the review"}`))
+		request.Header.Set("Idempotency-Key", key)
+
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+
+		review := &model.Review{}
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), review))
+		return review
+	}
+
+	first := create("request-1", "Title")
+	assert.Equal(t, first, create("request-1", "Resent title"))
+	assert.NotEqual(t, first.ID, create("request-2", "Title").ID)
+}
+
+func TestServer_ReadUpdateDelete(t *testing.T) {
+	server, service := newTestServer(t)
+
+	review := model.TestReview(t)
+	assert.NoError(t, service.Create(context.Background(), review))
+
+	response := serve(t, server, http.MethodGet, "/reviews/1", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	assert.NoError(t, service.Approve(context.Background(), &model.Moderation{ReviewID: review.ID, Moderator: "moderator@example.com"}))
+
+	response = serve(t, server, http.MethodGet, "/reviews/1", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	response = serve(t, server, http.MethodGet, "/reviews/2", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = serve(t, server, http.MethodGet, "/reviews/abc", "")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	response = serve(t, server, http.MethodPatch, "/reviews/1", `{"id": 5, "title": "Updated title"}`)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	updated := &model.Review{}
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), updated))
+	assert.Equal(t, review.ID, updated.ID)
+	assert.Equal(t, "Updated title", updated.Title)
+	assert.Equal(t, review.Description, updated.Description)
+	assert.Equal(t, model.StatusPending, updated.Status)
+
+	response = serve(t, server, http.MethodGet, "/reviews/1", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = serve(t, server, http.MethodPatch, "/reviews/2", `{"title": "Updated title"}`)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = serve(t, server, http.MethodDelete, "/reviews/1", "")
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Empty(t, response.Body.String())
+
+	response = serve(t, server, http.MethodDelete, "/reviews/1", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = serve(t, server, http.MethodPut, "/reviews/1", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, response.Code)
+}
+
+func TestServer_ReadPage(t *testing.T) {
+	server, service := newTestServer(t)
+
+	for i := 0; i < 3; i++ {
+		review := model.TestReview(t)
+		review.Rating = int8(i + 4)
+		assert.NoError(t, service.Create(context.Background(), review))
+		assert.NoError(t, service.Approve(context.Background(), &model.Moderation{ReviewID: review.ID, Moderator: "moderator@example.com"}))
+	}
+
+	testTable := []struct {
+		name           string
+		target         string
+		expectedCode   int
+		expectedLen    int
+		expectedCursor string
+	}{
+		{
+			name:           "paginated",
+			target:         "/reviews?limit=2",
+			expectedCode:   http.StatusOK,
+			expectedLen:    2,
+			expectedCursor: model.EncodeCursor(2),
+		},
+		{
+			name:         "filtered",
+			target:       "/reviews?min_rating=5&sort_by=rating&sort_order=desc",
+			expectedCode: http.StatusOK,
+			expectedLen:  2,
+		},
+		{
+			name:         "invalid limit",
+			target:       "/reviews?limit=ten",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid cursor",
+			target:       "/reviews?cursor=!",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			response := serve(t, server, http.MethodGet, testcase.target, "")
+			assert.Equal(t, testcase.expectedCode, response.Code)
+
+			if testcase.expectedCode == http.StatusOK {
+				page := &model.ReviewPage{}
+				assert.NoError(t, json.Unmarshal(response.Body.Bytes(), page))
+				assert.Len(t, page.Reviews, testcase.expectedLen)
+				assert.Equal(t, testcase.expectedCursor, page.NextCursor)
+			}
+		})
+	}
+}
+
+func TestServer_Health(t *testing.T) {
+	testTable := []struct {
+		name         string
+		check        httpapi.HealthCheck
+		expectedCode int
+	}{
+		{
+			name:         "up",
+			check:        func() error { return nil },
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "down",
+			check:        func() error { return errors.New("connection is not available") },
+			expectedCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			server, _ := newTestServer(t)
+			server.AddHealthCheck("rabbitmq", testcase.check)
+
+			response := serve(t, server, http.MethodGet, "/health", "")
+
+			assert.Equal(t, testcase.expectedCode, response.Code)
+
+			var health struct {
+				Status string            `json:"status"`
+				Checks map[string]string `json:"checks"`
+			}
+			assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &health))
+			assert.Contains(t, health.Checks, "rabbitmq")
+		})
+	}
+}
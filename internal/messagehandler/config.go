@@ -1,5 +1,15 @@
 package messagehandler
 
+import (
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/events"
+	"github.com/Restyx/golang-reviews-service/internal/grpcapi"
+	"github.com/Restyx/golang-reviews-service/internal/httpapi"
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/rabbitmq"
+)
+
 type Config struct {
 	PgUser      string `toml:"postgres_user"`
 	PgPassword  string `toml:"postgres_pass"`
@@ -10,6 +20,35 @@ type Config struct {
 	RmqPassword string `toml:"rabbitmq_pass"`
 	RmqHost     string `toml:"rabbitmq_host"`
 	RmqPort     string `toml:"rabbitmq_port"`
+
+	AuthToken       string `toml:"auth_token"`
+	MessageEnvelope bool   `toml:"message_envelope"`
+
+	ShutdownTimeout time.Duration `toml:"shutdown_timeout"`
+
+	Workers  int `toml:"workers"`
+	Prefetch int `toml:"prefetch"`
+
+	StatsPriorWeight float64 `toml:"stats_prior_weight"`
+
+	PurgeRetention time.Duration `toml:"purge_retention"`
+	PurgeInterval  time.Duration `toml:"purge_interval"`
+
+	ReportThreshold int `toml:"report_threshold"`
+
+	IdempotencyWindow time.Duration `toml:"idempotency_window"`
+
+	SearchLanguage string `toml:"search_language"`
+
+	Aspects model.AspectSchema `toml:"aspects"`
+
+	HTTP   httpapi.Config `toml:"http"`
+	GRPC   grpcapi.Config `toml:"grpc"`
+	Events events.Config  `toml:"events"`
+
+	Timeouts  TimeoutConfig            `toml:"timeouts"`
+	Retry     rabbitmq.RetryConfig     `toml:"retry"`
+	Reconnect rabbitmq.ReconnectConfig `toml:"reconnect"`
 }
 
 func NewConfig() *Config {
@@ -23,5 +62,51 @@ func NewConfig() *Config {
 		RmqPassword: "guest",
 		RmqHost:     "localhost",
 		RmqPort:     "5672",
+
+		ShutdownTimeout: 30 * time.Second,
+
+		Workers:  1,
+		Prefetch: 1,
+
+		StatsPriorWeight: 10,
+
+		PurgeRetention: 30 * 24 * time.Hour,
+		PurgeInterval:  time.Hour,
+
+		ReportThreshold: 3,
+
+		IdempotencyWindow: 24 * time.Hour,
+
+		SearchLanguage: model.DefaultSearchLanguage,
+
+		Aspects: model.AspectSchema{},
+
+		HTTP:   *httpapi.NewConfig(),
+		GRPC:   *grpcapi.NewConfig(),
+		Events: *events.NewConfig(),
+
+		Timeouts:  *NewTimeoutConfig(),
+		Retry:     *rabbitmq.NewRetryConfig(),
+		Reconnect: *rabbitmq.NewReconnectConfig(),
+	}
+}
+
+type TimeoutConfig struct {
+	Default    time.Duration            `toml:"default"`
+	Operations map[string]time.Duration `toml:"operations"`
+}
+
+func NewTimeoutConfig() *TimeoutConfig {
+	return &TimeoutConfig{
+		Default:    10 * time.Second,
+		Operations: map[string]time.Duration{},
 	}
 }
+
+func (c *TimeoutConfig) For(pattern string) time.Duration {
+	if timeout, ok := c.Operations[pattern]; ok {
+		return timeout
+	}
+
+	return c.Default
+}
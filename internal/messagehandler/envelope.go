@@ -0,0 +1,66 @@
+package messagehandler
+
+import (
+	"encoding/json"
+
+	"github.com/Restyx/golang-reviews-service/api/schemas"
+	"github.com/Restyx/golang-reviews-service/internal/transport"
+)
+
+type envelope struct {
+	transport.Message
+	message schemas.Message
+}
+
+func (e *envelope) Pattern() string {
+	return e.message.Pattern
+}
+
+func (e *envelope) Body() []byte {
+	return e.message.Data
+}
+
+func (e *envelope) CorrelationID() string {
+	if id := e.Message.CorrelationID(); id != "" {
+		return id
+	}
+
+	return e.message.ID
+}
+
+func (e *envelope) MessageID() string {
+	if id := e.Message.MessageID(); id != "" {
+		return id
+	}
+
+	return e.message.ID
+}
+
+func unwrapEnvelope(msg transport.Message) (transport.Message, bool) {
+	var message schemas.Message
+	if err := json.Unmarshal(msg.Body(), &message); err != nil || message.Pattern == "" {
+		return msg, false
+	}
+
+	return &envelope{Message: msg, message: message}, true
+}
+
+func encodeEnvelopeReply(msg transport.Message, body []byte, failed bool) []byte {
+	reply := schemas.MessageReply{
+		ID:         msg.CorrelationID(),
+		IsDisposed: true,
+	}
+
+	if failed {
+		reply.Err = body
+	} else if len(body) > 0 {
+		reply.Response = body
+	}
+
+	encoded, err := json.Marshal(reply)
+	if err != nil {
+		return body
+	}
+
+	return encoded
+}
@@ -0,0 +1,103 @@
+package messagehandler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Restyx/golang-reviews-service/api/schemas"
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const queuePattern = "reviews_queue"
+
+func encodeEnvelope(t *testing.T, pattern string, data any, id string) []byte {
+	t.Helper()
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := json.Marshal(schemas.Message{Pattern: pattern, Data: raw, ID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return body
+}
+
+func TestServer_Envelope(t *testing.T) {
+	router, bus := prepareRouter(t)
+	router.EnableEnvelopes()
+
+	messages, err := bus.Consume(append(router.Patterns(), queuePattern))
+	if err != nil {
+		t.Fatal(err)
+	}
+	go router.HandleMessages(messages)
+	t.Cleanup(bus.Close)
+
+	reply, err := bus.Request(queuePattern, encodeEnvelope(t, "reviews-create", model.TestReview(t), "create-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(http.StatusOK), reply.Code)
+
+	result := &schemas.MessageReply{}
+	assert.NoError(t, json.Unmarshal(reply.Body, result))
+	assert.True(t, result.IsDisposed)
+	assert.Empty(t, result.Err)
+
+	moderation := &model.Moderation{ReviewID: 1, Moderator: "moderator@example.com"}
+	reply, err = bus.Request(queuePattern, encodeEnvelope(t, "reviews-approve", moderation, "approve-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(http.StatusOK), reply.Code)
+
+	reply, err = bus.Request(queuePattern, encodeEnvelope(t, "reviews-get-one", map[string]int{"id": 1}, "get-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(http.StatusOK), reply.Code)
+
+	result = &schemas.MessageReply{}
+	assert.NoError(t, json.Unmarshal(reply.Body, result))
+	assert.NotEmpty(t, result.ID)
+	assert.True(t, result.IsDisposed)
+
+	review := &model.Review{}
+	assert.NoError(t, json.Unmarshal(result.Response, review))
+	assert.Equal(t, 1, review.ID)
+
+	reply, err = bus.Request(queuePattern, encodeEnvelope(t, "reviews-get-one", map[string]int{"id": 2}, "get-2"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(http.StatusNotFound), reply.Code)
+
+	result = &schemas.MessageReply{}
+	assert.NoError(t, json.Unmarshal(reply.Body, result))
+	assert.Empty(t, result.Response)
+
+	errorResponse := &schemas.ErrorResponse{}
+	assert.NoError(t, json.Unmarshal(result.Err, errorResponse))
+	assert.Equal(t, schemas.ErrorTypeNotFound, errorResponse.Error)
+
+	reply, err = bus.Request("reviews-get-one", []byte(`{"id":1}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(http.StatusOK), reply.Code)
+
+	review = &model.Review{}
+	assert.NoError(t, json.Unmarshal(reply.Body, review))
+	assert.Equal(t, 1, review.ID)
+}
+
+func TestServer_EnvelopeDisabled(t *testing.T) {
+	router, bus := prepareRouter(t)
+
+	messages, err := bus.Consume(append(router.Patterns(), queuePattern))
+	if err != nil {
+		t.Fatal(err)
+	}
+	go router.HandleMessages(messages)
+	t.Cleanup(bus.Close)
+
+	reply, err := bus.Request(queuePattern, encodeEnvelope(t, "reviews-create", model.TestReview(t), "create-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(http.StatusInternalServerError), reply.Code)
+}
@@ -1,15 +1,33 @@
 package messagehandler
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
-
+	"net"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/events"
+	"github.com/Restyx/golang-reviews-service/internal/grpcapi"
+	"github.com/Restyx/golang-reviews-service/internal/httpapi"
 	"github.com/Restyx/golang-reviews-service/internal/rabbitmq"
 	"github.com/Restyx/golang-reviews-service/internal/store/postgres"
+	"google.golang.org/grpc"
 )
 
 func Start(config *Config) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	return Run(ctx, config)
+}
+
+func Run(ctx context.Context, config *Config) error {
 	database, err := connectDB(config.PgUser, config.PgPassword, config.PgHost, config.PgPort, config.PgDB)
 	if err != nil {
 		return err
@@ -17,18 +35,62 @@ func Start(config *Config) error {
 	defer database.Close()
 
 	rmq := rabbitmq.New()
+	rmq.Retry = &config.Retry
+	rmq.Reconnect = &config.Reconnect
+	rmq.Prefetch = max(config.Prefetch, config.Workers)
 	if err := rmq.Connect(config.RmqUser, config.RmqPassword, config.RmqHost, config.RmqPort); err != nil {
 		return err
 	}
 	defer rmq.Close()
 
 	store := postgres.New(database)
+	store.EnableIdempotency(config.IdempotencyWindow)
+	store.SetSearchLanguage(config.SearchLanguage)
+
+	if config.Events.Enabled {
+		sink, err := rmq.EventSink(config.Events.Exchange)
+		if err != nil {
+			return err
+		}
+
+		store.EnableOutbox()
+
+		relay := events.NewRelay(store.Outbox(), sink, &config.Events)
+		go relay.Run()
+		defer relay.Close()
+	}
 
-	reviewsService := NewService(store)
+	reviewsService := NewService(store, config)
 
-	reviewsRouter := New(reviewsService, rmq.Channel)
+	reviewsRouter := New(reviewsService, rmq)
+	if config.AuthToken != "" {
+		reviewsRouter.Use(Auth(config.AuthToken))
+	}
+	reviewsRouter.Use(Validation(), Idempotency(), Timeout(&config.Timeouts))
+	reviewsRouter.SetWorkers(config.Workers, rmq.Prefetch)
+	if config.MessageEnvelope {
+		reviewsRouter.EnableEnvelopes()
+	}
+
+	go purgeDeleted(ctx, reviewsService, config.PurgeRetention, config.PurgeInterval)
+
+	if config.HTTP.Enabled {
+		httpServer := httpapi.New(reviewsService, &config.HTTP)
+		httpServer.AddHealthCheck("rabbitmq", rmq.Health)
+		httpServer.AddHealthCheck("postgres", database.Ping)
+
+		go serveHTTP(httpServer, config.HTTP.Addr)
+		defer shutdownHTTP(httpServer, config.ShutdownTimeout)
+	}
+
+	if config.GRPC.Enabled {
+		grpcServer := grpcapi.NewServer(reviewsService)
 
-	return listen(reviewsRouter)
+		go serveGRPC(grpcServer, config.GRPC.Addr)
+		defer shutdownGRPC(grpcServer, config.ShutdownTimeout)
+	}
+
+	return reviewsRouter.Listen(ctx, rmq, config.ShutdownTimeout)
 }
 
 func connectDB(user, password, host, port, datatbase string) (*sql.DB, error) {
@@ -45,31 +107,78 @@ func connectDB(user, password, host, port, datatbase string) (*sql.DB, error) {
 	return database, nil
 }
 
-func listen(Router *Server) error {
-	queue, err := Router.Channel.QueueDeclare("reviews_queue", true, false, false, false, nil)
-	if err != nil {
-		return err
+func purgeDeleted(ctx context.Context, service ServiceI, retention, interval time.Duration) {
+	if retention <= 0 || interval <= 0 {
+		return
 	}
 
-	for _, s := range []string{readReviewPattern, readReviewsPattern, createReviewPattern, updateReviewPattern, deleteReviewPattern} {
-		log.Printf("Binding queue %s to exchange %s with routing key %s", queue.Name, "reviews", s)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-		if err := Router.Channel.QueueBind(queue.Name, s, "reviews", false, nil); err != nil {
-			return err
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		count, err := service.Purge(ctx, retention)
+		if err != nil {
+			log.Printf("failed to purge deleted reviews: %s", err)
+			continue
+		}
+
+		if count > 0 {
+			log.Printf("purged %d deleted reviews", count)
 		}
 	}
+}
 
-	msgs, err := Router.Channel.Consume(queue.Name, "", false, false, false, false, nil)
-	if err != nil {
-		return err
+func serveHTTP(server *httpapi.Server, addr string) {
+	log.Printf("HTTP API listening on %s", addr)
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("HTTP API stopped: %s", err)
 	}
+}
+
+func shutdownHTTP(server *httpapi.Server, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("failed to shut down HTTP API: %s", err)
+	}
+}
 
-	forever := make(chan interface{})
+func shutdownGRPC(server *grpc.Server, timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-stopped:
+	case <-timer.C:
+		log.Printf("gRPC API did not stop within %s, closing open connections", timeout)
+		server.Stop()
+	}
+}
 
-	go Router.HandleMessages(msgs)
-	log.Printf("[*] Waiting for logs. To exit press CTRL+C")
+func serveGRPC(server *grpc.Server, addr string) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Printf("gRPC API failed to listen on %s: %s", addr, err)
+		return
+	}
 
-	<-forever
+	log.Printf("gRPC API listening on %s", addr)
 
-	return nil
+	if err := server.Serve(listener); err != nil {
+		log.Printf("gRPC API stopped: %s", err)
+	}
 }
@@ -0,0 +1,148 @@
+package messagehandler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/messagehandler"
+	"github.com/Restyx/golang-reviews-service/internal/transport/inprocess"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Listen(t *testing.T) {
+	testTable := []struct {
+		name          string
+		timeout       time.Duration
+		expectedError error
+	}{
+		{
+			name:          "drained",
+			timeout:       time.Second,
+			expectedError: nil,
+		},
+		{
+			name:          "timeout",
+			timeout:       10 * time.Millisecond,
+			expectedError: messagehandler.ErrShutdownTimeout,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			router, bus := prepareRouter(t)
+
+			started, release := make(chan struct{}), make(chan struct{})
+			router.Register("reviews-slow", messagehandler.Command(
+				func([]byte) (int, error) { return 0, nil },
+				func(context.Context, int) error {
+					close(started)
+					<-release
+					return nil
+				},
+			))
+
+			_, err := bus.Consume(router.Patterns())
+			assert.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			result := make(chan error)
+			go func() {
+				result <- router.Listen(ctx, bus, testcase.timeout)
+			}()
+
+			published := make(chan error)
+			go func() {
+				published <- bus.Publish("reviews-slow", []byte(`{}`))
+			}()
+
+			<-started
+			cancel()
+
+			if testcase.expectedError == nil {
+				time.Sleep(20 * time.Millisecond)
+				close(release)
+
+				assert.NoError(t, <-result)
+				assert.NoError(t, <-published)
+			} else {
+				assert.ErrorIs(t, <-result, testcase.expectedError)
+				close(release)
+			}
+
+			assert.ErrorIs(t, bus.Publish("reviews-slow", []byte(`{}`)), inprocess.ErrClosed)
+		})
+	}
+}
+
+func TestServer_Listen_ConsumerClosed(t *testing.T) {
+	router, bus := prepareRouter(t)
+
+	result := make(chan error)
+	go func() {
+		result <- router.Listen(context.Background(), bus, time.Second)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	bus.Close()
+
+	assert.ErrorIs(t, <-result, messagehandler.ErrConsumerClosed)
+}
+
+func TestServer_Expiration(t *testing.T) {
+	router, bus := prepareRouter(t)
+	router.Use(messagehandler.Timeout(&messagehandler.TimeoutConfig{
+		Default:    time.Second,
+		Operations: map[string]time.Duration{"reviews-deadline": time.Minute},
+	}))
+
+	deadlines := make(chan time.Time, 1)
+	router.Register("reviews-deadline", messagehandler.Command(
+		func([]byte) (int, error) { return 0, nil },
+		func(ctx context.Context, _ int) error {
+			deadline, _ := ctx.Deadline()
+			deadlines <- deadline
+			return nil
+		},
+	))
+	startRouter(t, router, bus)
+
+	testTable := []struct {
+		name           string
+		options        []inprocess.Option
+		expectHandled  bool
+		expectDeadline time.Duration
+	}{
+		{
+			name:           "operation timeout",
+			expectHandled:  true,
+			expectDeadline: time.Minute,
+		},
+		{
+			name:           "expiration",
+			options:        []inprocess.Option{inprocess.WithExpiration(5 * time.Second)},
+			expectHandled:  true,
+			expectDeadline: 5 * time.Second,
+		},
+		{
+			name:          "expired",
+			options:       []inprocess.Option{inprocess.WithExpiration(-time.Second)},
+			expectHandled: false,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			start := time.Now()
+			assert.NoError(t, bus.Publish("reviews-deadline", []byte(`{}`), testcase.options...))
+
+			if !testcase.expectHandled {
+				assert.Empty(t, deadlines)
+				return
+			}
+
+			deadline := <-deadlines
+			assert.WithinDuration(t, start.Add(testcase.expectDeadline), deadline, time.Second)
+		})
+	}
+}
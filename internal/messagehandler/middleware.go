@@ -0,0 +1,123 @@
+package messagehandler
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store"
+	"github.com/Restyx/golang-reviews-service/internal/transport"
+	"github.com/sirupsen/logrus"
+)
+
+const authHeader = "authorization"
+
+var ErrPanic = errors.New("panic handling")
+
+func Logging(logger *logrus.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(req *Request) (any, error) {
+			logger.Infof("received message key: '%s'", req.Message.Pattern())
+
+			result, err := next(req)
+			if err != nil {
+				logger.Warnf("handler for '%s' failed: %s", req.Message.Pattern(), err)
+			}
+
+			return result, err
+		}
+	}
+}
+
+func Recovery(logger *logrus.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(req *Request) (result any, err error) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					logger.Errorf("panic handling '%s': %v\n%s", req.Message.Pattern(), recovered, debug.Stack())
+					result, err = nil, fmt.Errorf("%w %s: %v", ErrPanic, req.Message.Pattern(), recovered)
+				}
+			}()
+
+			return next(req)
+		}
+	}
+}
+
+func Timing(logger *logrus.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(req *Request) (any, error) {
+			start := time.Now()
+			defer func() {
+				logger.Infof("handled '%s' in %s", req.Message.Pattern(), time.Since(start))
+			}()
+
+			return next(req)
+		}
+	}
+}
+
+func Auth(token string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(req *Request) (any, error) {
+			provided, _ := req.Message.Headers()[authHeader].(string)
+			if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				return nil, transport.ErrUnauthorized
+			}
+
+			return next(req)
+		}
+	}
+}
+
+func Validation() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(req *Request) (any, error) {
+			if payload, ok := req.Payload.(model.Model); ok {
+				if err := payload.Validate(); err != nil {
+					return nil, err
+				}
+			}
+
+			return next(req)
+		}
+	}
+}
+
+func Idempotency() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(req *Request) (any, error) {
+			key, _ := req.Message.Headers()[transport.IdempotencyKeyHeader].(string)
+			if key == "" {
+				key = req.Message.MessageID()
+			}
+
+			if key != "" {
+				req.Context = store.WithIdempotencyKey(req.Context, key)
+			}
+
+			return next(req)
+		}
+	}
+}
+
+func Timeout(timeouts *TimeoutConfig) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(req *Request) (any, error) {
+			timeout := timeouts.For(req.Message.Pattern())
+			if timeout <= 0 {
+				return next(req)
+			}
+
+			ctx, cancel := context.WithTimeout(req.Context, timeout)
+			defer cancel()
+			req.Context = ctx
+
+			return next(req)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package messagehandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Restyx/golang-reviews-service/internal/transport"
+)
+
+var ErrUnknownPattern = errors.New("invalid message routing key")
+
+type Request struct {
+	Context context.Context
+	Message transport.Message
+	Payload any
+}
+
+type HandlerFunc func(*Request) (any, error)
+
+type Middleware func(HandlerFunc) HandlerFunc
+
+type Route struct {
+	Decode  func([]byte) (any, error)
+	Handler HandlerFunc
+}
+
+func Command[T any](decode func([]byte) (T, error), handle func(context.Context, T) error) Route {
+	return Route{
+		Decode: decodeAny(decode),
+		Handler: func(req *Request) (any, error) {
+			return nil, handle(req.Context, req.Payload.(T))
+		},
+	}
+}
+
+func Query[T, R any](decode func([]byte) (T, error), handle func(context.Context, T) (R, error)) Route {
+	return Route{
+		Decode: decodeAny(decode),
+		Handler: func(req *Request) (any, error) {
+			return handle(req.Context, req.Payload.(T))
+		},
+	}
+}
+
+func decodeAny[T any](decode func([]byte) (T, error)) func([]byte) (any, error) {
+	return func(body []byte) (any, error) {
+		return decode(body)
+	}
+}
+
+type Registry struct {
+	routes     map[string]Route
+	patterns   []string
+	middleware []Middleware
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		routes: make(map[string]Route),
+	}
+}
+
+func (r *Registry) Register(pattern string, route Route) {
+	if _, ok := r.routes[pattern]; ok {
+		panic(fmt.Sprintf("messagehandler: pattern %s registered twice", pattern))
+	}
+
+	r.routes[pattern] = route
+	r.patterns = append(r.patterns, pattern)
+}
+
+func (r *Registry) Use(middleware ...Middleware) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+func (r *Registry) Patterns() []string {
+	patterns := make([]string, len(r.patterns))
+	copy(patterns, r.patterns)
+
+	return patterns
+}
+
+func (r *Registry) Dispatch(ctx context.Context, msg transport.Message) ([]byte, error) {
+	request := &Request{Context: ctx, Message: msg}
+
+	var handler HandlerFunc
+	if route, ok := r.routes[msg.Pattern()]; !ok {
+		handler = fail(ErrUnknownPattern)
+	} else if payload, err := route.Decode(msg.Body()); err != nil {
+		handler = fail(err)
+	} else {
+		request.Payload = payload
+		handler = route.Handler
+	}
+
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+
+	result, err := handler(request)
+	if err != nil || result == nil {
+		return nil, err
+	}
+
+	return json.Marshal(result)
+}
+
+func fail(err error) HandlerFunc {
+	return func(*Request) (any, error) {
+		return nil, err
+	}
+}
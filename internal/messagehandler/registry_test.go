@@ -0,0 +1,297 @@
+package messagehandler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/messagehandler"
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store"
+	"github.com/Restyx/golang-reviews-service/internal/store/testingstorage"
+	"github.com/Restyx/golang-reviews-service/internal/transport"
+	"github.com/Restyx/golang-reviews-service/internal/transport/inprocess"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func prepareRouter(t *testing.T, middleware ...messagehandler.Middleware) (*messagehandler.Server, *inprocess.Transport) {
+	t.Helper()
+
+	service := messagehandler.NewService(testingstorage.New(), messagehandler.NewConfig())
+
+	bus := inprocess.New()
+	router := messagehandler.New(service, bus)
+	router.Use(middleware...)
+
+	return router, bus
+}
+
+func startRouter(t *testing.T, router *messagehandler.Server, bus *inprocess.Transport) {
+	t.Helper()
+
+	messages, err := bus.Consume(router.Patterns())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go router.HandleMessages(messages)
+	t.Cleanup(bus.Close)
+}
+
+func TestRegistry_Patterns(t *testing.T) {
+	router, _ := prepareRouter(t)
+
+	patterns := router.Patterns()
+	assert.Contains(t, patterns, "reviews-create")
+	assert.Contains(t, patterns, "reviews-search")
+
+	router.Register("reviews-ping", messagehandler.Query(
+		func([]byte) (string, error) { return "ping", nil },
+		func(_ context.Context, s string) (string, error) { return s, nil },
+	))
+	assert.Contains(t, router.Patterns(), "reviews-ping")
+	assert.Len(t, router.Patterns(), len(patterns)+1)
+
+	assert.Panics(t, func() {
+		router.Register("reviews-ping", messagehandler.Command(
+			func([]byte) (int, error) { return 0, nil },
+			func(context.Context, int) error { return nil },
+		))
+	})
+}
+
+func TestRegistry_Dispatch(t *testing.T) {
+	router, bus := prepareRouter(t)
+
+	router.Register("reviews-echo", messagehandler.Query(
+		func(body []byte) (map[string]int, error) {
+			var data map[string]int
+			return data, json.Unmarshal(body, &data)
+		},
+		func(_ context.Context, data map[string]int) (map[string]int, error) { return data, nil },
+	))
+	router.Register("reviews-fail", messagehandler.Command(
+		func([]byte) (int, error) { return 0, nil },
+		func(context.Context, int) error { return errors.New("failed") },
+	))
+	startRouter(t, router, bus)
+
+	testcases := []struct {
+		name         string
+		pattern      string
+		body         string
+		expectedCode int32
+		expectedBody string
+	}{
+		{
+			name:         "query",
+			pattern:      "reviews-echo",
+			body:         `{"id":1}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"id":1}`,
+		},
+		{
+			name:         "decode error",
+			pattern:      "reviews-echo",
+			body:         `not json`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "handler error",
+			pattern:      "reviews-fail",
+			body:         `{}`,
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"code":500,"error":"internal","message":"failed"}`,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			reply, err := bus.Request(testcase.pattern, []byte(testcase.body))
+			assert.NoError(t, err)
+			assert.Equal(t, testcase.expectedCode, reply.Code)
+			if testcase.expectedBody != "" {
+				assert.Equal(t, testcase.expectedBody, string(reply.Body))
+			}
+		})
+	}
+}
+
+func TestMiddleware_Recovery(t *testing.T) {
+	router, bus := prepareRouter(t)
+
+	router.Register("reviews-panic", messagehandler.Command(
+		func([]byte) (int, error) { return 0, nil },
+		func(context.Context, int) error { panic("boom") },
+	))
+	startRouter(t, router, bus)
+
+	reply, err := bus.Request("reviews-panic", []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(http.StatusInternalServerError), reply.Code)
+	assert.Contains(t, string(reply.Body), "boom")
+}
+
+func TestMiddleware_Auth(t *testing.T) {
+	router, bus := prepareRouter(t, messagehandler.Auth("secret"))
+	startRouter(t, router, bus)
+
+	body, err := json.Marshal(model.TestReview(t))
+	assert.NoError(t, err)
+
+	reply, err := bus.Request("reviews-create", body)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(http.StatusUnauthorized), reply.Code)
+
+	reply, err = bus.Request("reviews-create", body, inprocess.WithHeader("authorization", "wrong"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(http.StatusUnauthorized), reply.Code)
+
+	reply, err = bus.Request("reviews-create", body, inprocess.WithHeader("authorization", "secret"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(http.StatusOK), reply.Code)
+}
+
+func TestMiddleware_Validation(t *testing.T) {
+	called := false
+	handler := messagehandler.Validation()(func(*messagehandler.Request) (any, error) {
+		called = true
+		return nil, nil
+	})
+
+	_, err := handler(&messagehandler.Request{Payload: &model.Review{Author: "not an email"}})
+	assert.Error(t, err)
+	assert.False(t, called)
+
+	_, err = handler(&messagehandler.Request{Payload: model.TestReview(t)})
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	_, err = handler(&messagehandler.Request{Payload: 1})
+	assert.NoError(t, err)
+}
+
+func TestMiddleware_Idempotency(t *testing.T) {
+	storage := testingstorage.New()
+	storage.EnableIdempotency(time.Hour)
+
+	bus := inprocess.New()
+	router := messagehandler.New(messagehandler.NewService(storage, messagehandler.NewConfig()), bus)
+	router.Use(messagehandler.Idempotency())
+	startRouter(t, router, bus)
+
+	body, err := json.Marshal(model.TestReview(t))
+	assert.NoError(t, err)
+
+	testTable := []struct {
+		name    string
+		options []inprocess.Option
+	}{
+		{
+			name:    "message id",
+			options: []inprocess.Option{inprocess.WithMessageID("create-1")},
+		},
+		{
+			name:    "header",
+			options: []inprocess.Option{inprocess.WithHeader(transport.IdempotencyKeyHeader, "create-2")},
+		},
+	}
+
+	create := func(t *testing.T, options []inprocess.Option) *model.Review {
+		reply, err := bus.Request("reviews-create", body, options...)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(http.StatusOK), reply.Code)
+
+		review := &model.Review{}
+		assert.NoError(t, json.Unmarshal(reply.Body, review))
+
+		return review
+	}
+
+	ids := make(map[int]bool)
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			first := create(t, testcase.options)
+			assert.NotZero(t, first.ID)
+			assert.False(t, ids[first.ID])
+			ids[first.ID] = true
+
+			second := create(t, testcase.options)
+			assert.Equal(t, first, second)
+		})
+	}
+
+	reviews, err := storage.Review().FindAll(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, reviews, 2)
+}
+
+func TestMiddleware_Order(t *testing.T) {
+	var calls []string
+	trace := func(name string) messagehandler.Middleware {
+		return func(next messagehandler.HandlerFunc) messagehandler.HandlerFunc {
+			return func(req *messagehandler.Request) (any, error) {
+				calls = append(calls, name)
+				return next(req)
+			}
+		}
+	}
+
+	router, bus := prepareRouter(t, trace("first"), trace("second"), messagehandler.Logging(logrus.New()))
+	startRouter(t, router, bus)
+
+	_, err := bus.Request("reviews-get-one", []byte(`{"id":1}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, calls)
+}
+
+func TestServer_Retry(t *testing.T) {
+	router, bus := prepareRouter(t)
+	bus.SetMaxRetries(3)
+
+	attempts := map[string]int{}
+	router.Register("reviews-transient", messagehandler.Command(
+		func([]byte) (int, error) { return 0, nil },
+		func(context.Context, int) error {
+			attempts["transient"]++
+			if attempts["transient"] < 3 {
+				return errors.New("connection reset")
+			}
+			return nil
+		},
+	))
+	router.Register("reviews-permanent", messagehandler.Command(
+		func([]byte) (int, error) { return 0, nil },
+		func(context.Context, int) error {
+			attempts["permanent"]++
+			return store.NewRecordNotFound("1")
+		},
+	))
+	router.Register("reviews-panic", messagehandler.Command(
+		func([]byte) (int, error) { return 0, nil },
+		func(context.Context, int) error {
+			attempts["panic"]++
+			panic("boom")
+		},
+	))
+	startRouter(t, router, bus)
+
+	reply, err := bus.Request("reviews-transient", []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(http.StatusOK), reply.Code)
+	assert.Equal(t, 3, attempts["transient"])
+
+	reply, err = bus.Request("reviews-permanent", []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(http.StatusNotFound), reply.Code)
+	assert.Equal(t, 1, attempts["permanent"])
+
+	reply, err = bus.Request("reviews-panic", []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(http.StatusInternalServerError), reply.Code)
+	assert.Equal(t, 1, attempts["panic"])
+}
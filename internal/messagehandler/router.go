@@ -1,187 +1,272 @@
 package messagehandler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/Restyx/golang-reviews-service/api/schemas"
 	"github.com/Restyx/golang-reviews-service/internal/model"
-	"github.com/Restyx/golang-reviews-service/internal/store"
-	"github.com/rabbitmq/amqp091-go"
+	"github.com/Restyx/golang-reviews-service/internal/transport"
 	"github.com/sirupsen/logrus"
 )
 
 const (
-	readReviewPattern   string = "reviews-get-one"
-	readReviewsPattern  string = "reviews-get-all"
-	createReviewPattern string = "reviews-create"
-	updateReviewPattern string = "reviews-update"
-	deleteReviewPattern string = "reviews-delete"
+	readReviewPattern      string = "reviews-get-one"
+	readReviewsPattern     string = "reviews-get-all"
+	createReviewPattern    string = "reviews-create"
+	updateReviewPattern    string = "reviews-update"
+	deleteReviewPattern    string = "reviews-delete"
+	restoreReviewPattern   string = "reviews-restore"
+	approveReviewPattern   string = "reviews-approve"
+	rejectReviewPattern    string = "reviews-reject"
+	reportReviewPattern    string = "reviews-report"
+	moderationQueuePattern string = "reviews-moderation-queue"
+	voteReviewPattern      string = "reviews-vote"
+
+	createReplyPattern string = "reviews-create-reply"
+	updateReplyPattern string = "reviews-update-reply"
+	deleteReplyPattern string = "reviews-delete-reply"
+	readRepliesPattern string = "reviews-get-replies"
+
+	readSubjectReviewsPattern  string = "reviews-get-by-subject"
+	countSubjectReviewsPattern string = "reviews-count-by-subject"
+	statsPattern               string = "reviews-stats"
+	historyPattern             string = "reviews-get-history"
+	searchPattern              string = "reviews-search"
+)
+
+var (
+	ErrConsumerClosed  = errors.New("message consumer closed unexpectedly")
+	ErrShutdownTimeout = errors.New("timed out waiting for in-flight messages")
 )
 
 type Server struct {
-	logger  logrus.Logger
-	service ServiceI
-	Channel *amqp091.Channel
-}
-
-func New(service ServiceI, channel *amqp091.Channel) *Server {
-	return &Server{
-		logger:  *logrus.New(),
-		service: service,
-		Channel: channel,
-	}
-}
-
-func (s *Server) HandleMessages(messages <-chan amqp091.Delivery) {
-	for msg := range messages {
-		s.logger.Infof("received message key: '%s'", msg.RoutingKey)
-
-		var (
-			nack   bool
-			reason error
-			body   []byte
-		)
-
-		switch msg.RoutingKey {
-		case readReviewPattern:
-			id, err := DecodeId(msg.Body)
-			if err != nil {
-				nack = true
-				reason = err
-				break
-			}
-
-			review, err := s.service.ReadOne(id)
-			if err != nil {
-				nack = true
-				reason = err
-				break
-			}
-
-			body, err = json.Marshal(review)
-			if err != nil {
-				nack = true
-				reason = err
-			}
-
-		case readReviewsPattern:
-			reviews, err := s.service.ReadAll()
-			if err != nil {
-				nack = true
-				reason = err
-				break
-
-			}
-
-			body, err = json.Marshal(reviews)
-			if err != nil {
-				nack = true
-				reason = err
-			}
-
-		case createReviewPattern:
-			review, err := DecodeReview(msg.Body)
-			if err != nil {
-				nack = true
-				reason = err
-				break
-			}
-
-			err = s.service.Create(review)
-			if err != nil {
-				nack = true
-				reason = err
-			}
-
-		case updateReviewPattern:
-			review, err := DecodeReview(msg.Body)
-			if err != nil {
-				nack = true
-				reason = err
-				break
-			}
-
-			err = s.service.Update(review)
-			if err != nil {
-				nack = true
-				reason = err
-			}
-
-		case deleteReviewPattern:
-			id, err := DecodeId(msg.Body)
-			if err != nil {
-				nack = true
-				reason = err
-				break
-			}
-
-			err = s.service.Delete(id)
-			if err != nil {
-				nack = true
-				reason = err
-			}
-
-		default:
-			nack = true
-			reason = errors.New("invalid message routing key")
+	logger    logrus.Logger
+	service   ServiceI
+	replier   transport.Replier
+	registry  *Registry
+	envelopes bool
+	workers   int
+	queueSize int
+	ordering  map[string]ordering
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+func New(service ServiceI, replier transport.Replier) *Server {
+	s := &Server{
+		logger:   *logrus.New(),
+		service:  service,
+		replier:  replier,
+		registry: NewRegistry(),
+		ordering: make(map[string]ordering),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	s.registry.Use(
+		Logging(&s.logger),
+		Timing(&s.logger),
+		Recovery(&s.logger),
+	)
+	s.routes()
+	s.orderings()
+
+	return s
+}
+
+func (s *Server) routes() {
+	s.registry.Register(readReviewPattern, Query(DecodeId, s.service.ReadOne))
+	s.registry.Register(readReviewsPattern, Query(DecodeQuery, s.service.ReadPage))
+	s.registry.Register(createReviewPattern, Query(DecodeReview, s.create))
+	s.registry.Register(updateReviewPattern, Command(DecodeReview, s.service.Update))
+	s.registry.Register(deleteReviewPattern, Command(DecodeId, s.service.Delete))
+	s.registry.Register(restoreReviewPattern, Command(DecodeId, s.service.Restore))
+	s.registry.Register(approveReviewPattern, Command(DecodeModeration, s.service.Approve))
+	s.registry.Register(rejectReviewPattern, Command(DecodeModeration, s.service.Reject))
+	s.registry.Register(reportReviewPattern, Command(DecodeReport, s.service.Report))
+	s.registry.Register(moderationQueuePattern, Query(DecodeQuery, s.service.ModerationQueue))
+	s.registry.Register(voteReviewPattern, Command(DecodeVote, s.service.Vote))
+
+	s.registry.Register(createReplyPattern, Command(DecodeReply, s.service.CreateReply))
+	s.registry.Register(updateReplyPattern, Command(DecodeReply, s.service.UpdateReply))
+	s.registry.Register(deleteReplyPattern, Command(DecodeId, s.service.DeleteReply))
+	s.registry.Register(readRepliesPattern, Query(DecodeId, s.service.Replies))
+
+	s.registry.Register(readSubjectReviewsPattern, Query(DecodeQuery, s.service.ReadBySubject))
+	s.registry.Register(countSubjectReviewsPattern, Query(DecodeSubject, s.service.CountBySubject))
+	s.registry.Register(statsPattern, Query(DecodeSubject, s.service.Stats))
+	s.registry.Register(historyPattern, Query(DecodeId, s.service.History))
+	s.registry.Register(searchPattern, Query(DecodeSearchQuery, s.service.Search))
+}
+
+func (s *Server) create(ctx context.Context, review *model.Review) (*model.Review, error) {
+	if err := s.service.Create(ctx, review); err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (s *Server) Use(middleware ...Middleware) {
+	s.registry.Use(middleware...)
+}
+
+func (s *Server) Register(pattern string, route Route) {
+	s.registry.Register(pattern, route)
+}
+
+func (s *Server) EnableEnvelopes() {
+	s.envelopes = true
+}
+
+func (s *Server) SetWorkers(workers, prefetch int) {
+	s.workers = workers
+	if workers > 0 {
+		s.queueSize = prefetch / workers
+	}
+}
+
+func (s *Server) Patterns() []string {
+	return s.registry.Patterns()
+}
+
+func (s *Server) Listen(ctx context.Context, consumer transport.Consumer, timeout time.Duration) error {
+	msgs, err := consumer.Consume(s.Patterns())
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		s.HandleMessages(msgs)
+	}()
+	s.logger.Infof("[*] Waiting for messages. To exit press CTRL+C")
+
+	select {
+	case <-done:
+		return ErrConsumerClosed
+	case <-ctx.Done():
+	}
+
+	s.logger.Infof("shutting down, waiting up to %s for in-flight messages", timeout)
+
+	if err := consumer.Cancel(); err != nil {
+		s.logger.Warnf("failed to cancel consumer: %s", err)
+	}
+
+	select {
+	case <-done:
+		s.logger.Infof("in-flight messages drained")
+		return nil
+	case <-time.After(timeout):
+		s.cancel()
+		return ErrShutdownTimeout
+	}
+}
+
+func (s *Server) HandleMessages(messages <-chan transport.Message) {
+	if s.workers <= 1 {
+		for msg := range messages {
+			s.handle(s.unwrap(msg))
 		}
+		return
+	}
 
-		if msg.ReplyTo != "" {
-			if nack {
-				body = []byte(fmt.Sprint(reason))
-			}
+	s.dispatch(messages)
+}
+
+func (s *Server) unwrap(msg transport.Message) *job {
+	if s.envelopes {
+		request, enveloped := unwrapEnvelope(msg)
+		return &job{msg: msg, request: request, enveloped: enveloped}
+	}
+
+	return &job{msg: msg, request: msg}
+}
+
+func (s *Server) handle(j *job) {
+	msg, request, enveloped := j.msg, j.request, j.enveloped
+
+	deadline, expires := msg.Deadline()
+	if expires && !time.Now().Before(deadline) {
+		s.logger.Warnf("skipping expired message '%s'", msg.Pattern())
+		msg.Ack()
+		return
+	}
+
+	ctx, cancel := s.ctx, context.CancelFunc(func() {})
+	if expires {
+		ctx, cancel = context.WithDeadline(s.ctx, deadline)
+	}
+	defer cancel()
 
-			s.logger.Infof("sending reply with status code %v", getStatusCode(reason))
-			err := s.reply(msg, getStatusCode(reason), body)
+	body, reason := s.registry.Dispatch(ctx, request)
+	nack := reason != nil
 
-			if err != nil {
-				nack = true
-				reason = err
-			}
+	if nack && isTransient(reason) {
+		err := msg.Retry()
+		if err == nil {
+			s.logger.Warnf("message scheduled for retry: %s", reason)
+			return
+		}
+		if !errors.Is(err, transport.ErrRetriesExhausted) {
+			s.logger.Errorf("failed to schedule retry: %s", err)
 		}
+	}
 
+	if msg.ReplyTo() != "" {
 		if nack {
-			s.logger.Errorf("message rejected: %s", reason)
-			msg.Nack(false, false)
-		} else {
-			s.logger.Infof("message acknowledged")
-			msg.Ack(false)
+			body = encodeError(reason)
+		}
+		if enveloped {
+			body = encodeEnvelopeReply(request, body, nack)
+		}
+
+		s.logger.Infof("sending reply with status code %v", getStatusCode(reason))
+		err := s.replier.Reply(msg, &transport.Reply{
+			Code: getStatusCode(reason),
+			Body: body,
+		})
+
+		if err != nil {
+			nack = true
+			reason = err
 		}
 	}
+
+	if nack {
+		s.logger.Errorf("message rejected: %s", reason)
+		msg.Nack()
+	} else {
+		s.logger.Infof("message acknowledged")
+		msg.Ack()
+	}
 }
 
-func (s *Server) reply(msg amqp091.Delivery, statusCode int32, body []byte) error {
-	return s.Channel.Publish(
-		"",
-		msg.ReplyTo,
-		false,
-		false,
-		amqp091.Publishing{
-			Headers: amqp091.Table{
-				"code": statusCode,
-			},
-			CorrelationId: msg.CorrelationId,
-			ContentType:   "application/json",
-			Body:          []byte(body),
-		},
-	)
+func isTransient(reason error) bool {
+	if errors.Is(reason, ErrUnknownPattern) || errors.Is(reason, ErrPanic) {
+		return false
+	}
+
+	return getStatusCode(reason) >= 500
 }
 
-func getStatusCode(inputError error) int32 {
-	var statusCode int32
-	switch {
-	case inputError == nil:
-		statusCode = 200
-	case errors.As(inputError, &store.ErrRecordNotFound):
-		statusCode = 404
-	case errors.As(inputError, &store.ErrFieldMissing):
-		statusCode = 400
-	default:
-		statusCode = 500
+func encodeError(reason error) []byte {
+	body, err := json.Marshal(schemas.NewErrorResponse(reason))
+	if err != nil {
+		return []byte(fmt.Sprint(reason))
 	}
 
-	return statusCode
+	return body
+}
+
+func getStatusCode(inputError error) int32 {
+	return int32(schemas.StatusCode(inputError))
 }
 
 func DecodeReview(body []byte) (*model.Review, error) {
@@ -204,6 +289,80 @@ func DecodeReviewSlice(body []byte) ([]model.Review, error) {
 	return review, nil
 }
 
+func DecodeQuery(body []byte) (*model.ReviewQuery, error) {
+	query := &model.ReviewQuery{}
+
+	if len(body) == 0 {
+		return query, nil
+	}
+
+	if err := json.Unmarshal(body, query); err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+func DecodeSearchQuery(body []byte) (*model.SearchQuery, error) {
+	query := &model.SearchQuery{}
+
+	if err := json.Unmarshal(body, query); err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+func DecodeSubject(body []byte) (*model.Subject, error) {
+	subject := &model.Subject{}
+
+	if err := json.Unmarshal(body, subject); err != nil {
+		return nil, err
+	}
+
+	return subject, nil
+}
+
+func DecodeModeration(body []byte) (*model.Moderation, error) {
+	moderation := &model.Moderation{}
+
+	if err := json.Unmarshal(body, moderation); err != nil {
+		return nil, err
+	}
+
+	return moderation, nil
+}
+
+func DecodeReport(body []byte) (*model.Report, error) {
+	report := &model.Report{}
+
+	if err := json.Unmarshal(body, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func DecodeVote(body []byte) (*model.Vote, error) {
+	vote := &model.Vote{}
+
+	if err := json.Unmarshal(body, vote); err != nil {
+		return nil, err
+	}
+
+	return vote, nil
+}
+
+func DecodeReply(body []byte) (*model.Reply, error) {
+	reply := &model.Reply{}
+
+	if err := json.Unmarshal(body, reply); err != nil {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
 func DecodeId(body []byte) (int, error) {
 	var data struct {
 		Id int `json:"id"`
@@ -1,45 +1,296 @@
 package messagehandler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/Restyx/golang-reviews-service/internal/model"
 	"github.com/Restyx/golang-reviews-service/internal/store"
 )
 
 type ServiceI interface {
-	Create(*model.Review) error
-	Update(*model.Review) error
-	Delete(int) error
-	ReadOne(int) (*model.Review, error)
-	ReadAll() ([]model.Review, error)
+	Create(context.Context, *model.Review) error
+	Update(context.Context, *model.Review) error
+	Delete(context.Context, int) error
+	Restore(context.Context, int) error
+	Approve(context.Context, *model.Moderation) error
+	Reject(context.Context, *model.Moderation) error
+	Report(context.Context, *model.Report) error
+	Vote(context.Context, *model.Vote) error
+	CreateReply(context.Context, *model.Reply) error
+	UpdateReply(context.Context, *model.Reply) error
+	DeleteReply(context.Context, int) error
+	Replies(context.Context, int) ([]model.Reply, error)
+	ModerationQueue(context.Context, *model.ReviewQuery) (*model.ReviewPage, error)
+	Purge(context.Context, time.Duration) (int, error)
+	ReadOne(context.Context, int) (*model.Review, error)
+	ReadAll(context.Context) ([]model.Review, error)
+	ReadPage(context.Context, *model.ReviewQuery) (*model.ReviewPage, error)
+	ReadBySubject(context.Context, *model.ReviewQuery) (*model.ReviewPage, error)
+	Search(context.Context, *model.SearchQuery) (*model.SearchPage, error)
+	CountBySubject(context.Context, *model.Subject) (*model.SubjectCount, error)
+	Stats(context.Context, *model.Subject) (*model.ReviewStats, error)
+	History(context.Context, int) ([]model.ReviewRevision, error)
 }
 
+const systemModerator = "system"
+
 type Service struct {
-	store store.StoreI
+	store  store.StoreI
+	config *Config
 }
 
-func NewService(store store.StoreI) ServiceI {
+func NewService(store store.StoreI, config *Config) ServiceI {
 	return &Service{
-		store: store,
+		store:  store,
+		config: config,
+	}
+}
+
+func (h *Service) Create(ctx context.Context, data *model.Review) error {
+	data.Status = model.StatusPending
+	data.HelpfulVotes, data.UnhelpfulVotes = 0, 0
+	data.MerchantResponse = nil
+
+	if err := h.checkAspects(ctx, data); err != nil {
+		return err
+	}
+
+	_, err := h.store.Review().Create(ctx, data)
+	return err
+}
+
+func (h *Service) Update(ctx context.Context, data *model.Review) error {
+	data.Status = ""
+	data.HelpfulVotes, data.UnhelpfulVotes = 0, 0
+	data.MerchantResponse = nil
+
+	if err := h.checkAspects(ctx, data); err != nil {
+		return err
+	}
+
+	if err := h.store.Review().Update(ctx, data); err != nil {
+		return err
+	}
+
+	return h.remoderate(ctx, data)
+}
+
+// remoderate sends an edited published review back to the moderation queue.
+// Reviews in any other status keep it.
+func (h *Service) remoderate(ctx context.Context, data *model.Review) error {
+	err := h.store.Review().SetStatus(ctx, &model.Moderation{
+		ReviewID:  data.ID,
+		Moderator: systemModerator,
+		Reason:    "edited",
+		Status:    model.StatusPending,
+	})
+
+	var transition *model.InvalidTransition
+	if errors.As(err, &transition) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	data.Status = model.StatusPending
+
+	return nil
+}
+
+func (h *Service) checkAspects(ctx context.Context, data *model.Review) error {
+	if len(data.Aspects) == 0 {
+		return nil
+	}
+
+	if err := data.Validate(); err != nil {
+		return err
+	}
+
+	subjectType := data.SubjectType
+	if data.ID != 0 {
+		review, err := h.store.Review().FindOne(ctx, data.ID)
+		if err != nil {
+			return err
+		}
+		subjectType = review.SubjectType
+	}
+
+	return h.config.Aspects.Check(subjectType, data.Aspects)
+}
+
+func (h *Service) Delete(ctx context.Context, id int) error {
+	return h.store.Review().Delete(ctx, id)
+}
+
+func (h *Service) Restore(ctx context.Context, id int) error {
+	return h.store.Review().Restore(ctx, id)
+}
+
+func (h *Service) Purge(ctx context.Context, retention time.Duration) (int, error) {
+	return h.store.Review().Purge(ctx, time.Now().Add(-retention))
+}
+
+func (h *Service) Approve(ctx context.Context, moderation *model.Moderation) error {
+	return h.moderate(ctx, moderation, model.StatusPublished)
+}
+
+func (h *Service) Reject(ctx context.Context, moderation *model.Moderation) error {
+	if moderation.Reason == "" {
+		return store.NewFieldMissing("reason")
+	}
+
+	return h.moderate(ctx, moderation, model.StatusRejected)
+}
+
+func (h *Service) Report(ctx context.Context, report *model.Report) error {
+	if _, err := h.store.Report().Create(ctx, report); err != nil {
+		return err
+	}
+
+	reporters, err := h.store.Report().CountReporters(ctx, report.ReviewID)
+	if err != nil {
+		return err
+	}
+
+	if h.config.ReportThreshold <= 0 || reporters < h.config.ReportThreshold {
+		return nil
 	}
+
+	review, err := h.store.Review().FindOne(ctx, report.ReviewID)
+	if err != nil {
+		return err
+	}
+
+	if !model.CanTransition(review.Status, model.StatusFlagged) {
+		return nil
+	}
+
+	err = h.store.Review().SetStatus(ctx, &model.Moderation{
+		ReviewID:  review.ID,
+		Moderator: systemModerator,
+		Reason:    fmt.Sprintf("reported by %d users", reporters),
+		Status:    model.StatusFlagged,
+	})
+
+	var transition *model.InvalidTransition
+	if errors.As(err, &transition) {
+		return nil
+	}
+
+	return err
+}
+
+func (h *Service) Vote(ctx context.Context, vote *model.Vote) error {
+	return h.store.Review().Vote(ctx, vote)
 }
 
-func (h *Service) Create(data *model.Review) error {
-	_, err := h.store.Review().Create(data)
+func (h *Service) CreateReply(ctx context.Context, reply *model.Reply) error {
+	_, err := h.store.Reply().Create(ctx, reply)
 	return err
 }
 
-func (h *Service) Update(data *model.Review) error {
-	return h.store.Review().Update(data)
+func (h *Service) UpdateReply(ctx context.Context, reply *model.Reply) error {
+	return h.store.Reply().Update(ctx, reply)
+}
+
+func (h *Service) DeleteReply(ctx context.Context, id int) error {
+	return h.store.Reply().Delete(ctx, id)
 }
 
-func (h *Service) Delete(id int) error {
-	return h.store.Review().Delete(id)
+func (h *Service) Replies(ctx context.Context, reviewID int) ([]model.Reply, error) {
+	return h.store.Reply().FindByReview(ctx, reviewID)
 }
 
-func (h *Service) ReadOne(id int) (*model.Review, error) {
-	return h.store.Review().FindOne(id)
+func (h *Service) ModerationQueue(ctx context.Context, query *model.ReviewQuery) (*model.ReviewPage, error) {
+	if query.Status == "" {
+		query.Status = model.StatusFlagged
+	}
+
+	return h.store.Review().FindPage(ctx, query)
+}
+
+func (h *Service) moderate(ctx context.Context, moderation *model.Moderation, status string) error {
+	if err := moderation.Validate(); err != nil {
+		return err
+	}
+
+	moderation.Status = status
+
+	return h.store.Review().SetStatus(ctx, moderation)
+}
+
+func (h *Service) ReadOne(ctx context.Context, id int) (*model.Review, error) {
+	review, err := h.store.Review().FindOne(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != model.StatusPublished {
+		return nil, store.NewRecordNotFound(fmt.Sprint(id))
+	}
+
+	response, err := h.store.Reply().FindLatest(ctx, review.ID, model.RoleMerchant)
+	if err != nil {
+		return nil, err
+	}
+
+	result := *review
+	result.MerchantResponse = response
+
+	return &result, nil
+}
+
+func (h *Service) ReadAll(ctx context.Context) ([]model.Review, error) {
+	return h.store.Review().FindAll(ctx)
+}
+
+func (h *Service) ReadPage(ctx context.Context, query *model.ReviewQuery) (*model.ReviewPage, error) {
+	query.Status = model.StatusPublished
+
+	return h.store.Review().FindPage(ctx, query)
+}
+
+func (h *Service) ReadBySubject(ctx context.Context, query *model.ReviewQuery) (*model.ReviewPage, error) {
+	if query.SubjectType == "" || query.SubjectID == "" {
+		return nil, store.NewFieldMissing("subject_type", "subject_id")
+	}
+
+	return h.ReadPage(ctx, query)
+}
+
+func (h *Service) Search(ctx context.Context, query *model.SearchQuery) (*model.SearchPage, error) {
+	query.Language = h.config.SearchLanguage
+	query.Status = model.StatusPublished
+
+	return h.store.Review().Search(ctx, query)
+}
+
+func (h *Service) CountBySubject(ctx context.Context, subject *model.Subject) (*model.SubjectCount, error) {
+	count, err := h.store.Review().CountBySubject(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.SubjectCount{
+		Subject: *subject,
+		Count:   count,
+	}, nil
+}
+
+func (h *Service) Stats(ctx context.Context, subject *model.Subject) (*model.ReviewStats, error) {
+	stats, err := h.store.Review().Stats(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Calculate(h.config.StatsPriorWeight)
+
+	return stats, nil
 }
 
-func (h *Service) ReadAll() ([]model.Review, error) {
-	return h.store.Review().FindAll()
+func (h *Service) History(ctx context.Context, id int) ([]model.ReviewRevision, error) {
+	return h.store.Review().FindRevisions(ctx, id)
 }
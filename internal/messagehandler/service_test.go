@@ -1,7 +1,9 @@
 package messagehandler_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/Restyx/golang-reviews-service/internal/messagehandler"
 	"github.com/Restyx/golang-reviews-service/internal/model"
@@ -9,17 +11,29 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func publishReview(t *testing.T, service messagehandler.ServiceI, review *model.Review) {
+	t.Helper()
+
+	if err := service.Create(context.Background(), review); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.Approve(context.Background(), &model.Moderation{ReviewID: review.ID, Moderator: "moderator@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestMessageHandlerService_Create(t *testing.T) {
-	service := messagehandler.NewService(testingstorage.New())
+	service := messagehandler.NewService(testingstorage.New(), messagehandler.NewConfig())
 
-	mockBehaviourFunc := func(review *model.Review) error {
-		return service.Create(review)
+	mockBehaviourFunc := func(ctx context.Context, review *model.Review) error {
+		return service.Create(ctx, review)
 	}
 
 	testTable := []struct {
 		name           string
 		inputReview    *model.Review
-		mockBehaviour  func(*model.Review) error
+		mockBehaviour  func(context.Context, *model.Review) error
 		expectedReview *model.Review
 		expectError    bool
 	}{
@@ -38,6 +52,7 @@ func TestMessageHandlerService_Create(t *testing.T) {
 				Rating:      3,
 				Title:       "Review Title",
 				Description: "Description of the review",
+				Status:      model.StatusPending,
 			},
 		},
 		{
@@ -68,10 +83,14 @@ func TestMessageHandlerService_Create(t *testing.T) {
 
 	for _, testcase := range testTable {
 		t.Run(testcase.name, func(t *testing.T) {
-			err := testcase.mockBehaviour(testcase.inputReview)
+			err := testcase.mockBehaviour(context.Background(), testcase.inputReview)
 
 			if !testcase.expectError {
 				assert.NoError(t, err)
+				assert.False(t, testcase.inputReview.CreatedAt.IsZero())
+
+				testcase.expectedReview.CreatedAt = testcase.inputReview.CreatedAt
+				testcase.expectedReview.UpdatedAt = testcase.inputReview.UpdatedAt
 				assert.EqualValues(t, testcase.inputReview, testcase.expectedReview)
 			} else {
 				assert.Error(t, err)
@@ -81,12 +100,13 @@ func TestMessageHandlerService_Create(t *testing.T) {
 }
 
 func TestMessageHandlerService_Update(t *testing.T) {
-	service := messagehandler.NewService(testingstorage.New())
+	storage := testingstorage.New()
+	service := messagehandler.NewService(storage, messagehandler.NewConfig())
 
-	mockBehaviourFunc := func(id int, updateReview *model.Review) error {
+	mockBehaviourFunc := func(ctx context.Context, id int, updateReview *model.Review) error {
 		updateReview.ID = id
 
-		return service.Update(updateReview)
+		return service.Update(ctx, updateReview)
 	}
 
 	baseReview := &model.Review{
@@ -100,7 +120,7 @@ func TestMessageHandlerService_Update(t *testing.T) {
 		name           string
 		inputReview    *model.Review
 		inputUpdate    *model.Review
-		mockBehaviour  func(int, *model.Review) error
+		mockBehaviour  func(context.Context, int, *model.Review) error
 		expectedReview *model.Review
 		expectError    bool
 	}{
@@ -120,6 +140,7 @@ func TestMessageHandlerService_Update(t *testing.T) {
 				Rating:      4,
 				Title:       "updated Review Title",
 				Description: "updated Description of the review",
+				Status:      model.StatusPending,
 			},
 		},
 		{
@@ -132,8 +153,8 @@ func TestMessageHandlerService_Update(t *testing.T) {
 				Title:       "updated Review Title",
 				Description: "updated Description of the review",
 			},
-			mockBehaviour: func(u int, r *model.Review) error {
-				return service.Update(r)
+			mockBehaviour: func(ctx context.Context, u int, r *model.Review) error {
+				return service.Update(ctx, r)
 			},
 			expectError: true,
 		},
@@ -149,14 +170,17 @@ func TestMessageHandlerService_Update(t *testing.T) {
 
 	for _, testcase := range testTable {
 		t.Run(testcase.name, func(t *testing.T) {
-			service.Create(testcase.inputReview)
-			err := testcase.mockBehaviour(testcase.inputReview.ID, testcase.inputUpdate)
+			service.Create(context.Background(), testcase.inputReview)
+			err := testcase.mockBehaviour(context.Background(), testcase.inputReview.ID, testcase.inputUpdate)
 
-			actualReview, _ := service.ReadOne(int(testcase.inputReview.ID))
+			actualReview, _ := storage.Review().FindOne(context.Background(), int(testcase.inputReview.ID))
 
 			if !testcase.expectError {
 				assert.NoError(t, err)
 				assert.EqualValues(t, actualReview, testcase.inputUpdate)
+
+				testcase.expectedReview.CreatedAt = actualReview.CreatedAt
+				testcase.expectedReview.UpdatedAt = actualReview.UpdatedAt
 				assert.EqualValues(t, actualReview, testcase.expectedReview)
 			} else {
 				assert.Error(t, err)
@@ -165,8 +189,81 @@ func TestMessageHandlerService_Update(t *testing.T) {
 	}
 }
 
+func TestMessageHandlerService_UpdateStatus(t *testing.T) {
+	config := messagehandler.NewConfig()
+	config.ReportThreshold = 2
+
+	storage := testingstorage.New()
+	service := messagehandler.NewService(storage, config)
+
+	moderate := func(t *testing.T, review *model.Review, status string) {
+		t.Helper()
+
+		if err := storage.Review().SetStatus(context.Background(), &model.Moderation{ReviewID: review.ID, Moderator: "moderator@example.com", Status: status}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testTable := []struct {
+		name           string
+		inputStatus    string
+		expectedStatus string
+	}{
+		{
+			name:           "published goes back to pending",
+			inputStatus:    model.StatusPublished,
+			expectedStatus: model.StatusPending,
+		},
+		{
+			name:           "rejected stays rejected",
+			inputStatus:    model.StatusRejected,
+			expectedStatus: model.StatusRejected,
+		},
+		{
+			name:           "flagged stays flagged",
+			inputStatus:    model.StatusFlagged,
+			expectedStatus: model.StatusFlagged,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			review := model.TestReview(t)
+			publishReview(t, service, review)
+			if testcase.inputStatus != model.StatusPublished {
+				moderate(t, review, testcase.inputStatus)
+			}
+
+			assert.NoError(t, service.Update(context.Background(), &model.Review{ID: review.ID, Title: "edited title"}))
+
+			actualReview, err := storage.Review().FindOne(context.Background(), review.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, testcase.expectedStatus, actualReview.Status)
+		})
+	}
+
+	review := model.TestReview(t)
+	publishReview(t, service, review)
+
+	report := model.TestReport(t, review.ID)
+	report.Reporter = "first@example.com"
+	assert.NoError(t, service.Report(context.Background(), report))
+
+	assert.NoError(t, service.Update(context.Background(), &model.Review{ID: review.ID, Title: "edited title"}))
+	moderate(t, review, model.StatusPublished)
+
+	report = model.TestReport(t, review.ID)
+	report.Reporter = "second@example.com"
+	assert.NoError(t, service.Report(context.Background(), report))
+
+	actualReview, err := storage.Review().FindOne(context.Background(), review.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.StatusPublished, actualReview.Status)
+}
+
 func TestMessageHandlerService_Delete(t *testing.T) {
-	service := messagehandler.NewService(testingstorage.New())
+	storage := testingstorage.New()
+	service := messagehandler.NewService(storage, messagehandler.NewConfig())
 
 	baseReview := &model.Review{
 		Author:      "example_mail@example.com",
@@ -177,7 +274,7 @@ func TestMessageHandlerService_Delete(t *testing.T) {
 
 	testTable := []struct {
 		name          string
-		mockBehaviour func(int) error
+		mockBehaviour func(context.Context, int) error
 		expectError   bool
 	}{
 		{
@@ -186,8 +283,8 @@ func TestMessageHandlerService_Delete(t *testing.T) {
 		},
 		{
 			name: "invalid id",
-			mockBehaviour: func(u int) error {
-				return service.Delete(0)
+			mockBehaviour: func(ctx context.Context, u int) error {
+				return service.Delete(ctx, 0)
 			},
 			expectError: true,
 		},
@@ -195,18 +292,18 @@ func TestMessageHandlerService_Delete(t *testing.T) {
 
 	for _, testcase := range testTable {
 		t.Run(testcase.name, func(t *testing.T) {
-			service.Create(baseReview)
-			err := testcase.mockBehaviour(int(baseReview.ID))
+			service.Create(context.Background(), baseReview)
+			err := testcase.mockBehaviour(context.Background(), int(baseReview.ID))
 
 			if !testcase.expectError {
 				assert.NoError(t, err)
 
-				actualReview, err := service.ReadOne(int(baseReview.ID))
+				actualReview, err := storage.Review().FindOne(context.Background(), int(baseReview.ID))
 				assert.Nil(t, actualReview)
 				assert.Error(t, err)
 			} else {
 				assert.Error(t, err)
-				actualReview, err := service.ReadOne(int(baseReview.ID))
+				actualReview, err := storage.Review().FindOne(context.Background(), int(baseReview.ID))
 				assert.NotNil(t, actualReview)
 				assert.NoError(t, err)
 
@@ -216,47 +313,46 @@ func TestMessageHandlerService_Delete(t *testing.T) {
 }
 
 func TestMessageHandlerService_ReadOne(t *testing.T) {
-	service := messagehandler.NewService(testingstorage.New())
+	service := messagehandler.NewService(testingstorage.New(), messagehandler.NewConfig())
 
-	baseReview := &model.Review{
-		Author:      "example_mail@example.com",
-		Rating:      3,
-		Title:       "Review Title",
-		Description: "Description of the review",
+	published := model.TestReview(t)
+	publishReview(t, service, published)
+
+	pending := model.TestReview(t)
+	if err := service.Create(context.Background(), pending); err != nil {
+		t.Fatal(err)
 	}
 
 	testTable := []struct {
-		name           string
-		inputReview    *model.Review
-		mockBehaviour  func(int) (*model.Review, error)
-		expectedReview *model.Review
-		expectError    bool
+		name        string
+		inputId     int
+		expectError bool
 	}{
 		{
-			name:           "valid",
-			inputReview:    baseReview,
-			mockBehaviour:  service.ReadOne,
-			expectedReview: baseReview,
+			name:    "valid",
+			inputId: published.ID,
+		},
+		{
+			name:        "pending review",
+			inputId:     pending.ID,
+			expectError: true,
 		},
 		{
 			name:        "invalid id",
-			inputReview: baseReview,
-			mockBehaviour: func(u int) (*model.Review, error) {
-				return service.ReadOne(0)
-			},
+			inputId:     0,
 			expectError: true,
 		},
 	}
 
 	for _, testcase := range testTable {
 		t.Run(testcase.name, func(t *testing.T) {
-			service.Create(baseReview)
-
-			resultReview, err := testcase.mockBehaviour(int(baseReview.ID))
+			resultReview, err := service.ReadOne(context.Background(), testcase.inputId)
 
 			if !testcase.expectError {
 				assert.NoError(t, err)
-				assert.EqualValues(t, baseReview, resultReview)
+				assert.Equal(t, published.ID, resultReview.ID)
+				assert.Equal(t, published.Title, resultReview.Title)
+				assert.Equal(t, model.StatusPublished, resultReview.Status)
 			} else {
 				assert.Error(t, err)
 				assert.Nil(t, resultReview)
@@ -273,17 +369,17 @@ func TestMessageHandlerService_ReadAll(t *testing.T) {
 		Description: "Description of the review",
 	}
 
-	mockBehaviourFunc := func(len int, service messagehandler.ServiceI) ([]model.Review, error) {
+	mockBehaviourFunc := func(ctx context.Context, len int, service messagehandler.ServiceI) ([]model.Review, error) {
 		for range len {
-			service.Create(baseReview)
+			service.Create(ctx, baseReview)
 		}
-		return service.ReadAll()
+		return service.ReadAll(ctx)
 	}
 
 	testTable := []struct {
 		name          string
 		inputLen      int
-		mockBehaviour func(int, messagehandler.ServiceI) ([]model.Review, error)
+		mockBehaviour func(context.Context, int, messagehandler.ServiceI) ([]model.Review, error)
 		expectError   bool
 	}{
 		{
@@ -300,9 +396,9 @@ func TestMessageHandlerService_ReadAll(t *testing.T) {
 
 	for _, testcase := range testTable {
 		t.Run(testcase.name, func(t *testing.T) {
-			service := messagehandler.NewService(testingstorage.New())
+			service := messagehandler.NewService(testingstorage.New(), messagehandler.NewConfig())
 
-			resultReviews, err := testcase.mockBehaviour(testcase.inputLen, service)
+			resultReviews, err := testcase.mockBehaviour(context.Background(), testcase.inputLen, service)
 
 			if !testcase.expectError {
 				assert.NoError(t, err)
@@ -314,3 +410,625 @@ func TestMessageHandlerService_ReadAll(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageHandlerService_ReadPage(t *testing.T) {
+	service := messagehandler.NewService(testingstorage.New(), messagehandler.NewConfig())
+
+	for range 5 {
+		publishReview(t, service, model.TestReview(t))
+	}
+	if err := service.Create(context.Background(), model.TestReview(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	testTable := []struct {
+		name           string
+		inputQuery     *model.ReviewQuery
+		expectedLen    int
+		expectedCursor string
+		expectError    bool
+	}{
+		{
+			name:           "first page",
+			inputQuery:     &model.ReviewQuery{Limit: 2},
+			expectedLen:    2,
+			expectedCursor: model.EncodeCursor(2),
+		},
+		{
+			name:        "last page",
+			inputQuery:  &model.ReviewQuery{Limit: 2, Cursor: model.EncodeCursor(4)},
+			expectedLen: 1,
+		},
+		{
+			name:        "invalid cursor",
+			inputQuery:  &model.ReviewQuery{Cursor: "%%%"},
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			page, err := service.ReadPage(context.Background(), testcase.inputQuery)
+
+			if !testcase.expectError {
+				assert.NoError(t, err)
+				assert.Len(t, page.Reviews, testcase.expectedLen)
+				assert.Equal(t, 5, page.Total)
+				assert.Equal(t, testcase.expectedCursor, page.NextCursor)
+			} else {
+				assert.Error(t, err)
+				assert.Nil(t, page)
+			}
+		})
+	}
+}
+
+func TestMessageHandlerService_BySubject(t *testing.T) {
+	service := messagehandler.NewService(testingstorage.New(), messagehandler.NewConfig())
+
+	for _, subjectID := range []string{"1", "1", "2"} {
+		review := model.TestReview(t)
+		review.SubjectType = "product"
+		review.SubjectID = subjectID
+
+		publishReview(t, service, review)
+	}
+
+	testTable := []struct {
+		name        string
+		inputQuery  *model.ReviewQuery
+		expectedLen int
+		expectError bool
+	}{
+		{
+			name:        "valid",
+			inputQuery:  &model.ReviewQuery{SubjectType: "product", SubjectID: "1"},
+			expectedLen: 2,
+		},
+		{
+			name:        "missing subject",
+			inputQuery:  &model.ReviewQuery{},
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			page, err := service.ReadBySubject(context.Background(), testcase.inputQuery)
+			count, countErr := service.CountBySubject(context.Background(), &model.Subject{Type: testcase.inputQuery.SubjectType, ID: testcase.inputQuery.SubjectID})
+
+			if !testcase.expectError {
+				assert.NoError(t, err)
+				assert.NoError(t, countErr)
+				assert.Len(t, page.Reviews, testcase.expectedLen)
+				assert.Equal(t, testcase.expectedLen, count.Count)
+			} else {
+				assert.Error(t, err)
+				assert.Error(t, countErr)
+			}
+		})
+	}
+}
+
+func TestMessageHandlerService_Stats(t *testing.T) {
+	service := messagehandler.NewService(testingstorage.New(), messagehandler.NewConfig())
+
+	for _, review := range []*model.Review{
+		{Author: "example_mail@example.com", Rating: 10, Title: "review title", Description: "review description", SubjectType: "product", SubjectID: "1"},
+		{Author: "example_mail@example.com", Rating: 8, Title: "review title", Description: "review description", SubjectType: "product", SubjectID: "1"},
+		{Author: "example_mail@example.com", Rating: 3, Title: "review title", Description: "review description", SubjectType: "product", SubjectID: "2"},
+	} {
+		publishReview(t, service, review)
+	}
+
+	pendingReview := model.TestReview(t)
+	pendingReview.SubjectType = "product"
+	pendingReview.SubjectID = "1"
+	if err := service.Create(context.Background(), pendingReview); err != nil {
+		t.Fatal(err)
+	}
+
+	testTable := []struct {
+		name              string
+		inputSubject      *model.Subject
+		expectedCount     int
+		expectedMean      float64
+		expectedWeighted  float64
+		expectedHistogram [model.HistogramSize]int
+		expectError       bool
+	}{
+		{
+			name:              "rated subject",
+			inputSubject:      &model.Subject{Type: "product", ID: "1"},
+			expectedCount:     2,
+			expectedMean:      9,
+			expectedWeighted:  (10*7 + 18) / 12.0,
+			expectedHistogram: [model.HistogramSize]int{7: 1, 9: 1},
+		},
+		{
+			name:             "unrated subject",
+			inputSubject:     &model.Subject{Type: "product", ID: "3"},
+			expectedWeighted: 7,
+		},
+		{
+			name:         "missing subject",
+			inputSubject: &model.Subject{},
+			expectError:  true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			stats, err := service.Stats(context.Background(), testcase.inputSubject)
+
+			if !testcase.expectError {
+				assert.NoError(t, err)
+				assert.Equal(t, testcase.expectedCount, stats.Count)
+				assert.InDelta(t, testcase.expectedMean, stats.Mean, 1e-9)
+				assert.InDelta(t, 7, stats.PriorMean, 1e-9)
+				assert.InDelta(t, testcase.expectedWeighted, stats.WeightedAverage, 1e-9)
+				assert.Equal(t, testcase.expectedHistogram, stats.Histogram)
+			} else {
+				assert.Error(t, err)
+				assert.Nil(t, stats)
+			}
+		})
+	}
+}
+
+func TestMessageHandlerService_History(t *testing.T) {
+	service := messagehandler.NewService(testingstorage.New(), messagehandler.NewConfig())
+
+	baseReview := model.TestReview(t)
+	if err := service.Create(context.Background(), baseReview); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.Update(context.Background(), &model.Review{ID: baseReview.ID, Rating: 7}); err != nil {
+		t.Fatal(err)
+	}
+
+	testTable := []struct {
+		name        string
+		inputId     int
+		expectedLen int
+		expectError bool
+	}{
+		{
+			name:        "valid",
+			inputId:     baseReview.ID,
+			expectedLen: 1,
+		},
+		{
+			name:        "invalid id",
+			inputId:     0,
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			revisions, err := service.History(context.Background(), testcase.inputId)
+
+			if !testcase.expectError {
+				assert.NoError(t, err)
+				assert.Len(t, revisions, testcase.expectedLen)
+				assert.EqualValues(t, 3, revisions[0].Rating)
+			} else {
+				assert.Error(t, err)
+				assert.Nil(t, revisions)
+			}
+		})
+	}
+}
+
+func TestMessageHandlerService_Restore(t *testing.T) {
+	storage := testingstorage.New()
+	service := messagehandler.NewService(storage, messagehandler.NewConfig())
+
+	baseReview := model.TestReview(t)
+	if err := service.Create(context.Background(), baseReview); err != nil {
+		t.Fatal(err)
+	}
+
+	testTable := []struct {
+		name          string
+		mockBehaviour func(context.Context, int) error
+		expectError   bool
+	}{
+		{
+			name: "valid",
+			mockBehaviour: func(ctx context.Context, id int) error {
+				if err := service.Delete(ctx, id); err != nil {
+					return err
+				}
+				return service.Restore(ctx, id)
+			},
+		},
+		{
+			name:          "not deleted",
+			mockBehaviour: service.Restore,
+			expectError:   true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			err := testcase.mockBehaviour(context.Background(), baseReview.ID)
+
+			if !testcase.expectError {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+
+			actualReview, err := storage.Review().FindOne(context.Background(), baseReview.ID)
+			assert.NoError(t, err)
+			assert.NotNil(t, actualReview)
+		})
+	}
+}
+
+func TestMessageHandlerService_Purge(t *testing.T) {
+	service := messagehandler.NewService(testingstorage.New(), messagehandler.NewConfig())
+
+	baseReview := model.TestReview(t)
+	if err := service.Create(context.Background(), baseReview); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Delete(context.Background(), baseReview.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := service.Purge(context.Background(), time.Hour)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	count, err = service.Purge(context.Background(), -time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Error(t, service.Restore(context.Background(), baseReview.ID))
+}
+
+func TestMessageHandlerService_Moderate(t *testing.T) {
+	storage := testingstorage.New()
+	service := messagehandler.NewService(storage, messagehandler.NewConfig())
+
+	baseReview := model.TestReview(t)
+	if err := service.Create(context.Background(), baseReview); err != nil {
+		t.Fatal(err)
+	}
+
+	testTable := []struct {
+		name           string
+		mockBehaviour  func(context.Context, *model.Moderation) error
+		inputReason    string
+		expectedStatus string
+		expectError    bool
+	}{
+		{
+			name:           "reject without reason",
+			mockBehaviour:  service.Reject,
+			expectedStatus: model.StatusPending,
+			expectError:    true,
+		},
+		{
+			name:           "approve pending",
+			mockBehaviour:  service.Approve,
+			expectedStatus: model.StatusPublished,
+		},
+		{
+			name:           "approve published",
+			mockBehaviour:  service.Approve,
+			expectedStatus: model.StatusPublished,
+			expectError:    true,
+		},
+		{
+			name:           "reject published",
+			mockBehaviour:  service.Reject,
+			inputReason:    "offensive language",
+			expectedStatus: model.StatusRejected,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			err := testcase.mockBehaviour(context.Background(), &model.Moderation{
+				ReviewID:  baseReview.ID,
+				Moderator: "moderator@example.com",
+				Reason:    testcase.inputReason,
+			})
+
+			if !testcase.expectError {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+
+			actualReview, err := storage.Review().FindOne(context.Background(), baseReview.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, testcase.expectedStatus, actualReview.Status)
+		})
+	}
+
+	page, err := service.ReadPage(context.Background(), &model.ReviewQuery{})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Reviews)
+
+	page, err = service.ReadPage(context.Background(), &model.ReviewQuery{Status: model.StatusRejected})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Reviews)
+
+	page, err = service.ModerationQueue(context.Background(), &model.ReviewQuery{Status: model.StatusRejected})
+	assert.NoError(t, err)
+	assert.Len(t, page.Reviews, 1)
+}
+
+func TestMessageHandlerService_Report(t *testing.T) {
+	config := messagehandler.NewConfig()
+	config.ReportThreshold = 2
+
+	storage := testingstorage.New()
+	service := messagehandler.NewService(storage, config)
+
+	baseReview := model.TestReview(t)
+	publishReview(t, service, baseReview)
+
+	testTable := []struct {
+		name           string
+		inputReporter  string
+		expectedStatus string
+		expectError    bool
+	}{
+		{
+			name:           "first report",
+			inputReporter:  "first@example.com",
+			expectedStatus: model.StatusPublished,
+		},
+		{
+			name:           "duplicate report",
+			inputReporter:  "first@example.com",
+			expectedStatus: model.StatusPublished,
+		},
+		{
+			name:           "threshold reached",
+			inputReporter:  "second@example.com",
+			expectedStatus: model.StatusFlagged,
+		},
+		{
+			name:           "already flagged",
+			inputReporter:  "third@example.com",
+			expectedStatus: model.StatusFlagged,
+		},
+		{
+			name:           "missing reporter",
+			expectedStatus: model.StatusFlagged,
+			expectError:    true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			report := model.TestReport(t, baseReview.ID)
+			report.Reporter = testcase.inputReporter
+
+			err := service.Report(context.Background(), report)
+
+			if !testcase.expectError {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+
+			actualReview, err := storage.Review().FindOne(context.Background(), baseReview.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, testcase.expectedStatus, actualReview.Status)
+		})
+	}
+
+	page, err := service.ReadPage(context.Background(), &model.ReviewQuery{})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Reviews)
+
+	queue, err := service.ModerationQueue(context.Background(), &model.ReviewQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, queue.Reviews, 1)
+	assert.Equal(t, baseReview.ID, queue.Reviews[0].ID)
+
+	assert.NoError(t, service.Approve(context.Background(), &model.Moderation{ReviewID: baseReview.ID, Moderator: "moderator@example.com"}))
+
+	for _, step := range []struct {
+		reporter       string
+		expectedStatus string
+	}{
+		{reporter: "first@example.com", expectedStatus: model.StatusPublished},
+		{reporter: "first@example.com", expectedStatus: model.StatusPublished},
+		{reporter: "fourth@example.com", expectedStatus: model.StatusFlagged},
+	} {
+		report := model.TestReport(t, baseReview.ID)
+		report.Reporter = step.reporter
+		assert.NoError(t, service.Report(context.Background(), report))
+
+		actualReview, err := storage.Review().FindOne(context.Background(), baseReview.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, step.expectedStatus, actualReview.Status)
+	}
+}
+
+func TestMessageHandlerService_Vote(t *testing.T) {
+	storage := testingstorage.New()
+	service := messagehandler.NewService(storage, messagehandler.NewConfig())
+
+	baseReview := model.TestReview(t)
+	publishReview(t, service, baseReview)
+
+	assert.NoError(t, service.Vote(context.Background(), model.TestVote(t, baseReview.ID, true)))
+	assert.Error(t, service.Vote(context.Background(), model.TestVote(t, baseReview.ID+1, true)))
+
+	updatedReview := &model.Review{ID: baseReview.ID, Title: "Updated title", HelpfulVotes: 100}
+	assert.NoError(t, service.Update(context.Background(), updatedReview))
+
+	actualReview, err := storage.Review().FindOne(context.Background(), baseReview.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, actualReview.HelpfulVotes)
+	assert.Equal(t, 0, actualReview.UnhelpfulVotes)
+}
+
+func TestMessageHandlerService_Replies(t *testing.T) {
+	service := messagehandler.NewService(testingstorage.New(), messagehandler.NewConfig())
+
+	baseReview := model.TestReview(t)
+	publishReview(t, service, baseReview)
+
+	actualReview, err := service.ReadOne(context.Background(), baseReview.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, actualReview.MerchantResponse)
+
+	merchantReply := model.TestReply(t, baseReview.ID)
+	assert.NoError(t, service.CreateReply(context.Background(), merchantReply))
+
+	customerReply := model.TestReply(t, baseReview.ID)
+	customerReply.Role = model.RoleCustomer
+	customerReply.ParentID = merchantReply.ID
+	assert.NoError(t, service.CreateReply(context.Background(), customerReply))
+
+	assert.Error(t, service.CreateReply(context.Background(), model.TestReply(t, baseReview.ID+1)))
+
+	assert.NoError(t, service.UpdateReply(context.Background(), &model.Reply{ID: merchantReply.ID, Body: "Sorry to hear that"}))
+
+	actualReview, err = service.ReadOne(context.Background(), baseReview.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, actualReview.MerchantResponse) {
+		assert.Equal(t, merchantReply.ID, actualReview.MerchantResponse.ID)
+		assert.Equal(t, "Sorry to hear that", actualReview.MerchantResponse.Body)
+	}
+
+	replies, err := service.Replies(context.Background(), baseReview.ID)
+	assert.NoError(t, err)
+	assert.Len(t, replies, 2)
+
+	assert.NoError(t, service.DeleteReply(context.Background(), merchantReply.ID))
+
+	actualReview, err = service.ReadOne(context.Background(), baseReview.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, actualReview.MerchantResponse)
+
+	replies, err = service.Replies(context.Background(), baseReview.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, replies)
+}
+
+func TestMessageHandlerService_Aspects(t *testing.T) {
+	config := messagehandler.NewConfig()
+	config.Aspects = model.AspectSchema{
+		"hotel": {"cleanliness", "value"},
+	}
+
+	storage := testingstorage.New()
+	service := messagehandler.NewService(storage, config)
+
+	hotelReview := func(aspects map[string]int8) *model.Review {
+		review := model.TestReview(t)
+		review.SubjectType = "Hotel"
+		review.SubjectID = "h-1"
+		review.Aspects = aspects
+		return review
+	}
+
+	first := hotelReview(map[string]int8{"cleanliness": 8, "value": 6})
+	publishReview(t, service, first)
+
+	second := hotelReview(map[string]int8{"cleanliness": 4})
+	publishReview(t, service, second)
+
+	var unknownAspect *model.UnknownAspect
+	assert.ErrorAs(t, service.Create(context.Background(), hotelReview(map[string]int8{"battery_life": 5})), &unknownAspect)
+	assert.Error(t, service.Create(context.Background(), hotelReview(map[string]int8{"value": 11})))
+
+	assert.ErrorAs(t, service.Update(context.Background(), &model.Review{ID: second.ID, Aspects: map[string]int8{"battery_life": 5}}), &unknownAspect)
+	assert.NoError(t, service.Update(context.Background(), &model.Review{ID: second.ID, Aspects: map[string]int8{"value": 10}}))
+
+	actualReview, err := storage.Review().FindOne(context.Background(), second.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int8(3), actualReview.Rating)
+	assert.Equal(t, map[string]int8{"cleanliness": 4, "value": 10}, actualReview.Aspects)
+	assert.Equal(t, model.StatusPending, actualReview.Status)
+
+	assert.NoError(t, service.Approve(context.Background(), &model.Moderation{ReviewID: second.ID, Moderator: "moderator@example.com"}))
+
+	stats, err := service.Stats(context.Background(), &model.Subject{Type: "hotel", ID: "h-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.Count)
+	assert.Equal(t, &model.AspectStats{Count: 2, Sum: 12, Mean: 6}, stats.Aspects["cleanliness"])
+	assert.Equal(t, &model.AspectStats{Count: 2, Sum: 16, Mean: 8}, stats.Aspects["value"])
+}
+
+func TestMessageHandlerService_Search(t *testing.T) {
+	service := messagehandler.NewService(testingstorage.New(), messagehandler.NewConfig())
+
+	published := model.TestReview(t)
+	published.Title = "Battery champion"
+	publishReview(t, service, published)
+
+	pending := model.TestReview(t)
+	pending.Title = "Battery disaster"
+	assert.NoError(t, service.Create(context.Background(), pending))
+
+	query := &model.SearchQuery{Query: "battery", Language: "german"}
+	page, err := service.Search(context.Background(), query)
+	assert.NoError(t, err)
+	assert.Equal(t, model.DefaultSearchLanguage, query.Language)
+	assert.Equal(t, 1, page.Total)
+	assert.Equal(t, published.ID, page.Results[0].ID)
+
+	_, err = service.Search(context.Background(), &model.SearchQuery{})
+	assert.Error(t, err)
+
+	config := messagehandler.NewConfig()
+	config.SearchLanguage = "german"
+	service = messagehandler.NewService(testingstorage.New(), config)
+
+	query = &model.SearchQuery{Query: "akku"}
+	_, err = service.Search(context.Background(), query)
+	assert.NoError(t, err)
+	assert.Equal(t, "german", query.Language)
+}
+
+func TestMessageHandlerService_Events(t *testing.T) {
+	store := testingstorage.New()
+	store.EnableOutbox()
+	service := messagehandler.NewService(store, messagehandler.NewConfig())
+
+	review := model.TestReview(t)
+	rating := review.Rating
+	assert.NoError(t, service.Create(context.Background(), review))
+	assert.NoError(t, service.Update(context.Background(), &model.Review{ID: review.ID, Rating: 9}))
+	assert.NoError(t, service.Delete(context.Background(), review.ID))
+	assert.Error(t, service.Delete(context.Background(), review.ID))
+
+	var events []model.Event
+	_, err := store.Outbox().Process(context.Background(), 10, func(message *model.OutboxMessage) error {
+		events = append(events, message.Event)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	if !assert.Len(t, events, 3) {
+		return
+	}
+
+	created, updated, deleted := events[0], events[1], events[2]
+
+	assert.Equal(t, model.EventReviewCreated, created.Type)
+	assert.Nil(t, created.Before)
+	assert.Equal(t, review.ID, created.After.ID)
+
+	assert.Equal(t, model.EventReviewUpdated, updated.Type)
+	assert.Equal(t, rating, updated.Before.Rating)
+	assert.Equal(t, int8(9), updated.After.Rating)
+
+	assert.Equal(t, model.EventReviewDeleted, deleted.Type)
+	assert.Equal(t, review.ID, deleted.ReviewID)
+	assert.Equal(t, int8(9), deleted.Before.Rating)
+	assert.Nil(t, deleted.After)
+}
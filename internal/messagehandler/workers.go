@@ -0,0 +1,104 @@
+package messagehandler
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+
+	"github.com/Restyx/golang-reviews-service/internal/transport"
+)
+
+type job struct {
+	msg       transport.Message
+	request   transport.Message
+	enveloped bool
+}
+
+func (s *Server) dispatch(messages <-chan transport.Message) {
+	queues := make([]chan *job, s.workers)
+	wg := sync.WaitGroup{}
+
+	for i := range queues {
+		queues[i] = make(chan *job, s.queueSize)
+
+		wg.Add(1)
+		go func(queue <-chan *job) {
+			defer wg.Done()
+
+			for j := range queue {
+				s.handle(j)
+			}
+		}(queues[i])
+	}
+
+	next := 0
+	for msg := range messages {
+		j := s.unwrap(msg)
+
+		worker := next
+		if key, ok := s.orderingKey(j.request); ok {
+			worker = int(hashKey(key) % uint32(s.workers))
+		} else {
+			next = (next + 1) % s.workers
+		}
+
+		queues[worker] <- j
+	}
+
+	for _, queue := range queues {
+		close(queue)
+	}
+	wg.Wait()
+}
+
+type ordering struct {
+	entity string
+	field  string
+}
+
+// Order runs messages for pattern that act on the same entity on the same
+// worker, in delivery order. field names the body field holding the entity id;
+// messages without it are spread across workers. Ordering only holds for the
+// first delivery: a message sent back through Retry is redelivered behind
+// later messages for the same entity.
+func (s *Server) Order(pattern, entity, field string) {
+	s.ordering[pattern] = ordering{entity: entity, field: field}
+}
+
+func (s *Server) orderings() {
+	for _, pattern := range []string{readReviewPattern, updateReviewPattern, deleteReviewPattern, restoreReviewPattern, approveReviewPattern, rejectReviewPattern, historyPattern, readRepliesPattern} {
+		s.Order(pattern, "review", "id")
+	}
+	for _, pattern := range []string{reportReviewPattern, voteReviewPattern, createReplyPattern} {
+		s.Order(pattern, "review", "review_id")
+	}
+	for _, pattern := range []string{updateReplyPattern, deleteReplyPattern} {
+		s.Order(pattern, "reply", "id")
+	}
+}
+
+func (s *Server) orderingKey(request transport.Message) (string, bool) {
+	ordering, ok := s.ordering[request.Pattern()]
+	if !ok {
+		return "", false
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(request.Body(), &fields); err != nil {
+		return "", false
+	}
+
+	var id json.Number
+	if err := json.Unmarshal(fields[ordering.field], &id); err != nil || id == "" {
+		return "", false
+	}
+
+	return ordering.entity + ":" + id.String(), true
+}
+
+func hashKey(key string) uint32 {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+
+	return hash.Sum32()
+}
@@ -0,0 +1,241 @@
+package messagehandler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/api/schemas"
+	"github.com/Restyx/golang-reviews-service/internal/messagehandler"
+	"github.com/Restyx/golang-reviews-service/internal/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+type testMessage struct {
+	pattern string
+	body    []byte
+	acked   atomic.Bool
+}
+
+func (m *testMessage) Pattern() string                 { return m.pattern }
+func (m *testMessage) Body() []byte                    { return m.body }
+func (m *testMessage) ReplyTo() string                 { return "" }
+func (m *testMessage) CorrelationID() string           { return "" }
+func (m *testMessage) MessageID() string               { return "" }
+func (m *testMessage) Headers() map[string]interface{} { return nil }
+func (m *testMessage) Deadline() (time.Time, bool)     { return time.Time{}, false }
+func (m *testMessage) Ack() error                      { m.acked.Store(true); return nil }
+func (m *testMessage) Nack() error                     { return nil }
+func (m *testMessage) Retry() error                    { return transport.ErrRetriesExhausted }
+
+func TestServer_Workers(t *testing.T) {
+	type step struct {
+		ReviewID int `json:"review_id"`
+		Seq      int `json:"seq"`
+	}
+
+	const reviews, steps = 8, 20
+
+	router, _ := prepareRouter(t)
+	router.SetWorkers(4, 4)
+	router.Order("reviews-step", "review", "review_id")
+
+	mu := sync.Mutex{}
+	processed := make(map[int][]int)
+	var active, peak atomic.Int32
+
+	router.Register("reviews-step", messagehandler.Command(
+		func(body []byte) (*step, error) {
+			s := &step{}
+			return s, json.Unmarshal(body, s)
+		},
+		func(_ context.Context, s *step) error {
+			if current := active.Add(1); current > peak.Load() {
+				peak.Store(current)
+			}
+			defer active.Add(-1)
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+			processed[s.ReviewID] = append(processed[s.ReviewID], s.Seq)
+
+			return nil
+		},
+	))
+
+	messages := make(chan transport.Message, reviews*steps)
+	sent := make([]*testMessage, 0, reviews*steps)
+	for seq := 0; seq < steps; seq++ {
+		for id := 1; id <= reviews; id++ {
+			msg := &testMessage{
+				pattern: "reviews-step",
+				body:    []byte(fmt.Sprintf(`{"review_id": %d, "seq": %d}`, id, seq)),
+			}
+			sent = append(sent, msg)
+			messages <- msg
+		}
+	}
+	close(messages)
+
+	router.HandleMessages(messages)
+
+	for _, msg := range sent {
+		assert.True(t, msg.acked.Load())
+	}
+
+	for id := 1; id <= reviews; id++ {
+		assert.Len(t, processed[id], steps)
+		assert.IsIncreasing(t, processed[id])
+	}
+
+	assert.Greater(t, peak.Load(), int32(1))
+}
+
+func TestServer_Workers_Errors(t *testing.T) {
+	const requests = 32
+
+	router, bus := prepareRouter(t)
+	router.SetWorkers(4, 4)
+	startRouter(t, router, bus)
+
+	wg := sync.WaitGroup{}
+	for id := 1; id <= requests; id++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+
+			reply, err := bus.Request("reviews-get-one", []byte(fmt.Sprintf(`{"id": %d}`, id)))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			response := &schemas.ErrorResponse{}
+			assert.NoError(t, json.Unmarshal(reply.Body, response))
+			assert.Equal(t, fmt.Sprintf("record %d not found", id), response.Message)
+		}(id)
+	}
+	wg.Wait()
+}
+
+func TestServer_Workers_SlowKey(t *testing.T) {
+	type step struct {
+		ReviewID int `json:"review_id"`
+	}
+
+	const others = 6
+
+	router, _ := prepareRouter(t)
+	router.SetWorkers(2, 2*(others+2))
+	router.Order("reviews-step", "review", "review_id")
+
+	release := make(chan struct{})
+	progressed := make(chan int, others)
+
+	router.Register("reviews-step", messagehandler.Command(
+		func(body []byte) (*step, error) {
+			s := &step{}
+			return s, json.Unmarshal(body, s)
+		},
+		func(_ context.Context, s *step) error {
+			if s.ReviewID == 1 {
+				<-release
+				return nil
+			}
+
+			progressed <- s.ReviewID
+			return nil
+		},
+	))
+
+	messages := make(chan transport.Message, others+2)
+	sent := make([]*testMessage, 0, others+2)
+	for _, id := range []int{1, 1, 2, 3, 4, 5, 6, 7} {
+		msg := &testMessage{
+			pattern: "reviews-step",
+			body:    []byte(fmt.Sprintf(`{"review_id": %d}`, id)),
+		}
+		sent = append(sent, msg)
+		messages <- msg
+	}
+	close(messages)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		router.HandleMessages(messages)
+	}()
+
+	select {
+	case <-progressed:
+	case <-time.After(time.Second):
+		t.Fatal("other keys did not progress while review 1 was blocked")
+	}
+
+	close(release)
+	<-done
+
+	for _, msg := range sent {
+		assert.True(t, msg.acked.Load())
+	}
+}
+
+func TestServer_Workers_Replies(t *testing.T) {
+	type step struct {
+		ID  int `json:"id"`
+		Seq int `json:"seq"`
+	}
+
+	const replies, steps = 8, 10
+
+	mu := sync.Mutex{}
+	processed := make(map[int][]int)
+
+	record := func(next messagehandler.HandlerFunc) messagehandler.HandlerFunc {
+		return func(req *messagehandler.Request) (any, error) {
+			s := &step{}
+			if err := json.Unmarshal(req.Message.Body(), s); err != nil {
+				return nil, err
+			}
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+			processed[s.ID] = append(processed[s.ID], s.Seq)
+
+			return nil, nil
+		}
+	}
+
+	router, _ := prepareRouter(t, record)
+	router.SetWorkers(4, 4)
+
+	messages := make(chan transport.Message, replies*steps)
+	for seq := 0; seq < steps; seq++ {
+		for id := 1; id <= replies; id++ {
+			msg := &testMessage{
+				pattern: "reviews-delete-reply",
+				body:    []byte(fmt.Sprintf(`{"id": %d, "seq": %d}`, id, seq)),
+			}
+			if seq%2 == 0 {
+				msg.pattern = "reviews-update-reply"
+				msg.body = []byte(fmt.Sprintf(`{"id": %d, "review_id": %d, "seq": %d}`, id, seq*replies+id, seq))
+			}
+			messages <- msg
+		}
+	}
+	close(messages)
+
+	router.HandleMessages(messages)
+
+	for id := 1; id <= replies; id++ {
+		assert.Len(t, processed[id], steps)
+		assert.IsIncreasing(t, processed[id])
+	}
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+)
+
+type AspectSchema map[string][]string
+
+type AspectStats struct {
+	Count int     `json:"count"`
+	Sum   int     `json:"-"`
+	Mean  float64 `json:"mean"`
+}
+
+func (s AspectSchema) Check(subjectType string, aspects map[string]int8) error {
+	names := make([]string, 0, len(aspects))
+	for name := range aspects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !s.allows(subjectType, name) {
+			return NewUnknownAspect(subjectType, name)
+		}
+	}
+
+	return nil
+}
+
+func (s AspectSchema) allows(subjectType, aspect string) bool {
+	for _, name := range s[subjectType] {
+		if name == aspect {
+			return true
+		}
+	}
+
+	return false
+}
+
+type UnknownAspect struct {
+	subjectType string
+	aspect      string
+}
+
+func NewUnknownAspect(subjectType, aspect string) *UnknownAspect {
+	return &UnknownAspect{subjectType: subjectType, aspect: aspect}
+}
+
+func (e *UnknownAspect) Error() string {
+	if e.subjectType == "" {
+		return fmt.Sprintf("aspect %s requires a subject type", e.aspect)
+	}
+
+	return fmt.Sprintf("aspect %s is not defined for subject type %s", e.aspect, e.subjectType)
+}
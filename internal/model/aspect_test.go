@@ -0,0 +1,53 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAspectSchema_Check(t *testing.T) {
+	schema := model.AspectSchema{
+		"hotel":       {"cleanliness", "value", "location"},
+		"electronics": {"battery_life", "value"},
+	}
+
+	testcases := []struct {
+		name        string
+		subjectType string
+		aspects     map[string]int8
+		isValid     bool
+	}{
+		{name: "no aspects", subjectType: "hotel", isValid: true},
+		{name: "no aspects without subject", isValid: true},
+		{name: "known aspects", subjectType: "hotel", aspects: map[string]int8{"cleanliness": 7, "value": 9}, isValid: true},
+		{name: "aspect of another type", subjectType: "hotel", aspects: map[string]int8{"battery_life": 4}, isValid: false},
+		{name: "type without schema", subjectType: "book", aspects: map[string]int8{"value": 4}, isValid: false},
+		{name: "aspects without subject", aspects: map[string]int8{"value": 4}, isValid: false},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			err := schema.Check(testcase.subjectType, testcase.aspects)
+			if testcase.isValid {
+				assert.NoError(t, err)
+			} else {
+				var unknownAspect *model.UnknownAspect
+				assert.ErrorAs(t, err, &unknownAspect)
+			}
+		})
+	}
+}
+
+func TestReviewStats_Aspects(t *testing.T) {
+	stats := &model.ReviewStats{}
+	stats.AddAspects(map[string]int8{"cleanliness": 8, "value": 6})
+	stats.AddAspects(map[string]int8{"cleanliness": 6, "value": 0})
+	stats.AddAspects(nil)
+	stats.Calculate(0)
+
+	assert.Len(t, stats.Aspects, 2)
+	assert.Equal(t, &model.AspectStats{Count: 2, Sum: 14, Mean: 7}, stats.Aspects["cleanliness"])
+	assert.Equal(t, &model.AspectStats{Count: 1, Sum: 6, Mean: 6}, stats.Aspects["value"])
+}
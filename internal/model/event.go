@@ -0,0 +1,60 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+const (
+	EventReviewCreated = "review.created"
+	EventReviewUpdated = "review.updated"
+	EventReviewDeleted = "review.deleted"
+
+	EventSource = "reviews-service"
+)
+
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Source     string    `json:"source"`
+	ReviewID   int       `json:"review_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Before     *Review   `json:"before"`
+	After      *Review   `json:"after"`
+}
+
+type OutboxMessage struct {
+	ID        int        `json:"id"`
+	Event     Event      `json:"event"`
+	CreatedAt time.Time  `json:"created_at"`
+	SentAt    *time.Time `json:"sent_at,omitempty"`
+}
+
+func NewEvent(eventType string, before, after *Review) *Event {
+	event := &Event{
+		ID:         newEventID(),
+		Type:       eventType,
+		Source:     EventSource,
+		OccurredAt: time.Now().UTC(),
+		Before:     before,
+		After:      after,
+	}
+
+	if after != nil {
+		event.ReviewID = after.ID
+	} else if before != nil {
+		event.ReviewID = before.ID
+	}
+
+	return event
+}
+
+func newEventID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return time.Now().UTC().Format("20060102150405.000000000")
+	}
+
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,33 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEvent(t *testing.T) {
+	before := &model.Review{ID: 3, Rating: 4}
+	after := &model.Review{ID: 3, Rating: 5}
+
+	created := model.NewEvent(model.EventReviewCreated, nil, after)
+	assert.Equal(t, 3, created.ReviewID)
+	assert.Equal(t, model.EventSource, created.Source)
+	assert.Len(t, created.ID, 32)
+	assert.False(t, created.OccurredAt.IsZero())
+
+	deleted := model.NewEvent(model.EventReviewDeleted, before, nil)
+	assert.Equal(t, 3, deleted.ReviewID)
+	assert.Nil(t, deleted.After)
+	assert.NotEqual(t, created.ID, deleted.ID)
+}
+
+func TestReview_Clone(t *testing.T) {
+	review := &model.Review{ID: 1, Aspects: map[string]int8{"value": 4}}
+
+	clone := review.Clone()
+	clone.Aspects["value"] = 9
+
+	assert.Equal(t, int8(4), review.Aspects["value"])
+}
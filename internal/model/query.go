@@ -0,0 +1,97 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	"github.com/leebenson/conform"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type ReviewQuery struct {
+	Limit       int    `json:"limit" validate:"gte=0,lte=100"`
+	Offset      int    `json:"offset" validate:"gte=0"`
+	Cursor      string `json:"cursor" conform:"trim"`
+	SortBy      string `json:"sort_by" validate:"omitempty,oneof=id rating title author helpfulness" conform:"trim,lower"`
+	SortOrder   string `json:"sort_order" validate:"omitempty,oneof=asc desc" conform:"trim,lower"`
+	MinRating   int8   `json:"min_rating" validate:"omitempty,gte=1,lte=10"`
+	MaxRating   int8   `json:"max_rating" validate:"omitempty,gte=1,lte=10,gtefield=MinRating"`
+	Author      string `json:"author" validate:"omitempty,email" conform:"trim"`
+	SubjectType string `json:"subject_type" validate:"required_with=SubjectID,omitempty,lte=50" conform:"trim,lower"`
+	SubjectID   string `json:"subject_id" validate:"required_with=SubjectType,omitempty,lte=100" conform:"trim"`
+	Status      string `json:"status" validate:"omitempty,oneof=pending published rejected flagged" conform:"trim,lower"`
+}
+
+type ReviewPage struct {
+	Reviews    []Review `json:"reviews"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+	Total      int      `json:"total"`
+}
+
+func (q *ReviewQuery) Validate() error {
+	validate := newValidator()
+	if err := conform.Strings(q); err != nil {
+		return err
+	}
+
+	if err := validate.Struct(q); err != nil {
+		return err
+	}
+
+	if q.Cursor != "" {
+		offset, err := DecodeCursor(q.Cursor)
+		if err != nil {
+			return err
+		}
+		q.Offset = offset
+	}
+
+	if q.Limit == 0 {
+		q.Limit = DefaultPageLimit
+	}
+	if q.SortBy == "" {
+		q.SortBy = "id"
+	}
+	if q.SortOrder == "" {
+		q.SortOrder = "asc"
+	}
+
+	return nil
+}
+
+func (q *ReviewQuery) NextCursor(count, total int) string {
+	return nextCursor(q.Offset, count, total)
+}
+
+func nextCursor(offset, count, total int) string {
+	if next := offset + count; count > 0 && next < total {
+		return EncodeCursor(next)
+	}
+
+	return ""
+}
+
+func EncodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func DecodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, ErrInvalidCursor
+	}
+
+	return offset, nil
+}
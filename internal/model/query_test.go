@@ -0,0 +1,134 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewQuery_Validate(t *testing.T) {
+	testcases := []struct {
+		name          string
+		query         *model.ReviewQuery
+		expectedQuery *model.ReviewQuery
+		isValid       bool
+	}{
+		{
+			name:  "defaults",
+			query: &model.ReviewQuery{},
+			expectedQuery: &model.ReviewQuery{
+				Limit:     model.DefaultPageLimit,
+				SortBy:    "id",
+				SortOrder: "asc",
+			},
+			isValid: true,
+		},
+		{
+			name: "cursor overrides offset",
+			query: &model.ReviewQuery{
+				Limit:     5,
+				Offset:    2,
+				Cursor:    model.EncodeCursor(10),
+				SortBy:    "Rating",
+				SortOrder: "DESC",
+			},
+			expectedQuery: &model.ReviewQuery{
+				Limit:     5,
+				Offset:    10,
+				Cursor:    model.EncodeCursor(10),
+				SortBy:    "rating",
+				SortOrder: "desc",
+			},
+			isValid: true,
+		},
+		{
+			name:    "invalid cursor",
+			query:   &model.ReviewQuery{Cursor: "not a cursor"},
+			isValid: false,
+		},
+		{
+			name:    "limit too high",
+			query:   &model.ReviewQuery{Limit: model.MaxPageLimit + 1},
+			isValid: false,
+		},
+		{
+			name:    "negative offset",
+			query:   &model.ReviewQuery{Offset: -1},
+			isValid: false,
+		},
+		{
+			name:    "unknown sort field",
+			query:   &model.ReviewQuery{SortBy: "description"},
+			isValid: false,
+		},
+		{
+			name:    "inverted rating range",
+			query:   &model.ReviewQuery{MinRating: 7, MaxRating: 3},
+			isValid: false,
+		},
+		{
+			name:    "invalid author",
+			query:   &model.ReviewQuery{Author: "invalid"},
+			isValid: false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			err := testcase.query.Validate()
+
+			if testcase.isValid {
+				assert.NoError(t, err)
+				assert.EqualValues(t, testcase.expectedQuery, testcase.query)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestReviewQuery_NextCursor(t *testing.T) {
+	testcases := []struct {
+		name           string
+		query          *model.ReviewQuery
+		count          int
+		total          int
+		expectedCursor string
+	}{
+		{
+			name:           "more pages",
+			query:          &model.ReviewQuery{Offset: 0, Limit: 2},
+			count:          2,
+			total:          5,
+			expectedCursor: model.EncodeCursor(2),
+		},
+		{
+			name:           "last page",
+			query:          &model.ReviewQuery{Offset: 4, Limit: 2},
+			count:          1,
+			total:          5,
+			expectedCursor: "",
+		},
+		{
+			name:           "empty page",
+			query:          &model.ReviewQuery{Offset: 10, Limit: 2},
+			count:          0,
+			total:          5,
+			expectedCursor: "",
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			cursor := testcase.query.NextCursor(testcase.count, testcase.total)
+			assert.Equal(t, testcase.expectedCursor, cursor)
+
+			if cursor != "" {
+				offset, err := model.DecodeCursor(cursor)
+				assert.NoError(t, err)
+				assert.Equal(t, testcase.query.Offset+testcase.count, offset)
+			}
+		})
+	}
+}
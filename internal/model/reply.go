@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/leebenson/conform"
+)
+
+const (
+	RoleMerchant = "merchant"
+	RoleCustomer = "customer"
+)
+
+type Reply struct {
+	ID        int       `json:"id" validate:"omitempty"`
+	ReviewID  int       `json:"review_id" validate:"required_without=ID"`
+	ParentID  int       `json:"parent_id" validate:"omitempty"`
+	Author    string    `json:"author" validate:"required_without=ID,omitempty,lte=100" conform:"trim"`
+	Role      string    `json:"role" validate:"required_without=ID,omitempty,oneof=merchant customer" conform:"trim,lower"`
+	Body      string    `json:"body" validate:"required,lte=1000" conform:"trim"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (r *Reply) Validate() error {
+	validate := newValidator()
+	if err := conform.Strings(r); err != nil {
+		return err
+	}
+
+	return validate.Struct(r)
+}
@@ -0,0 +1,85 @@
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReply_Validate(t *testing.T) {
+	testcases := []struct {
+		name    string
+		reply   func() *model.Reply
+		isValid bool
+	}{
+		{
+			name: "valid",
+			reply: func() *model.Reply {
+				return model.TestReply(t, 1)
+			},
+			isValid: true,
+		},
+		{
+			name: "role is normalized",
+			reply: func() *model.Reply {
+				reply := model.TestReply(t, 1)
+				reply.Role = " Customer "
+				return reply
+			},
+			isValid: true,
+		},
+		{
+			name: "update with body only",
+			reply: func() *model.Reply {
+				return &model.Reply{ID: 1, Body: "Updated answer"}
+			},
+			isValid: true,
+		},
+		{
+			name: "empty review id",
+			reply: func() *model.Reply {
+				return model.TestReply(t, 0)
+			},
+			isValid: false,
+		},
+		{
+			name: "unknown role",
+			reply: func() *model.Reply {
+				reply := model.TestReply(t, 1)
+				reply.Role = "admin"
+				return reply
+			},
+			isValid: false,
+		},
+		{
+			name: "blank body",
+			reply: func() *model.Reply {
+				reply := model.TestReply(t, 1)
+				reply.Body = "   "
+				return reply
+			},
+			isValid: false,
+		},
+		{
+			name: "long body",
+			reply: func() *model.Reply {
+				reply := model.TestReply(t, 1)
+				reply.Body = strings.Repeat("a", 1001)
+				return reply
+			},
+			isValid: false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			if testcase.isValid {
+				assert.NoError(t, testcase.reply().Validate())
+			} else {
+				assert.Error(t, testcase.reply().Validate())
+			}
+		})
+	}
+}
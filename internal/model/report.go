@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/leebenson/conform"
+)
+
+type Report struct {
+	ID        int       `json:"id"`
+	ReviewID  int       `json:"review_id" validate:"required"`
+	Reporter  string    `json:"reporter" validate:"required,lte=100" conform:"trim"`
+	Reason    string    `json:"reason" validate:"required,oneof=spam abuse offensive off_topic fake other" conform:"trim,lower"`
+	Note      string    `json:"note" validate:"lte=500" conform:"trim"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (r *Report) Validate() error {
+	validate := newValidator()
+	if err := conform.Strings(r); err != nil {
+		return err
+	}
+
+	return validate.Struct(r)
+}
@@ -0,0 +1,77 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport_Validate(t *testing.T) {
+	testcases := []struct {
+		name    string
+		report  func() *model.Report
+		isValid bool
+	}{
+		{
+			name: "valid",
+			report: func() *model.Report {
+				return model.TestReport(t, 1)
+			},
+			isValid: true,
+		},
+		{
+			name: "reason is normalized",
+			report: func() *model.Report {
+				report := model.TestReport(t, 1)
+				report.Reason = " Off_Topic "
+				return report
+			},
+			isValid: true,
+		},
+		{
+			name: "empty review id",
+			report: func() *model.Report {
+				return model.TestReport(t, 0)
+			},
+			isValid: false,
+		},
+		{
+			name: "empty reporter",
+			report: func() *model.Report {
+				report := model.TestReport(t, 1)
+				report.Reporter = "  "
+				return report
+			},
+			isValid: false,
+		},
+		{
+			name: "unknown reason",
+			report: func() *model.Report {
+				report := model.TestReport(t, 1)
+				report.Reason = "boring"
+				return report
+			},
+			isValid: false,
+		},
+		{
+			name: "long note",
+			report: func() *model.Report {
+				report := model.TestReport(t, 1)
+				report.Note = generateRandomString(t, 501)
+				return report
+			},
+			isValid: false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			if testcase.isValid {
+				assert.NoError(t, testcase.report().Validate())
+			} else {
+				assert.Error(t, testcase.report().Validate())
+			}
+		})
+	}
+}
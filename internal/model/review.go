@@ -1,7 +1,8 @@
 package model
 
 import (
-	"github.com/go-playground/validator/v10"
+	"time"
+
 	"github.com/leebenson/conform"
 )
 
@@ -10,18 +11,57 @@ type Model interface {
 }
 
 type Review struct {
-	ID          int    `json:"id" validate:"omitempty"`
-	Author      string `json:"author" validate:"required_without=ID,omitempty,email" conform:"trim"`
-	Rating      int8   `json:"rating" validate:"required_without=ID,omitempty,gte=1,lte=10"`
-	Title       string `json:"title" validate:"required_without=ID,omitempty,gte=3,lte=50" conform:"trim"`
-	Description string `json:"description" validate:"required_without=ID,omitempty,gte=3,lte=500" conform:"trim"`
+	ID               int             `json:"id" validate:"omitempty"`
+	Author           string          `json:"author" validate:"required_without=ID,omitempty,email" conform:"trim"`
+	Rating           int8            `json:"rating" validate:"required_without=ID,omitempty,gte=1,lte=10"`
+	Aspects          map[string]int8 `json:"aspects,omitempty" validate:"omitempty,dive,keys,required,lte=50,endkeys,gte=1,lte=10"`
+	Title            string          `json:"title" validate:"required_without=ID,omitempty,gte=3,lte=50" conform:"trim"`
+	Description      string          `json:"description" validate:"required_without=ID,omitempty,gte=3,lte=500" conform:"trim"`
+	SubjectType      string          `json:"subject_type" validate:"required_with=SubjectID,omitempty,lte=50" conform:"trim,lower"`
+	SubjectID        string          `json:"subject_id" validate:"required_with=SubjectType,omitempty,lte=100" conform:"trim"`
+	Status           string          `json:"status" validate:"omitempty,oneof=pending published rejected flagged"`
+	HelpfulVotes     int             `json:"helpful_votes"`
+	UnhelpfulVotes   int             `json:"unhelpful_votes"`
+	MerchantResponse *Reply          `json:"merchant_response,omitempty" validate:"-"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+type ReviewRevision struct {
+	ID          int             `json:"id"`
+	ReviewID    int             `json:"review_id"`
+	Author      string          `json:"author"`
+	Rating      int8            `json:"rating"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Aspects     map[string]int8 `json:"aspects,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	RevisedAt   time.Time       `json:"revised_at"`
 }
 
 func (r *Review) Validate() error {
-	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate := newValidator()
 	if err := conform.Strings(r); err != nil {
 		return err
 	}
 
 	return validate.Struct(r)
 }
+
+func (r *Review) Clone() *Review {
+	clone := *r
+
+	if r.Aspects != nil {
+		clone.Aspects = make(map[string]int8, len(r.Aspects))
+		for name, rating := range r.Aspects {
+			clone.Aspects[name] = rating
+		}
+	}
+
+	if r.MerchantResponse != nil {
+		response := *r.MerchantResponse
+		clone.MerchantResponse = &response
+	}
+
+	return &clone
+}
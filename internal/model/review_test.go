@@ -82,6 +82,33 @@ func TestReview_Validate(t *testing.T) {
 			},
 			isValid: false,
 		},
+		{
+			name: "valid aspects",
+			review: func() *model.Review {
+				review := model.TestReview(t)
+				review.Aspects = map[string]int8{"cleanliness": 8, "value": 10}
+				return review
+			},
+			isValid: true,
+		},
+		{
+			name: "invalid aspect rating",
+			review: func() *model.Review {
+				review := model.TestReview(t)
+				review.Aspects = map[string]int8{"cleanliness": 11}
+				return review
+			},
+			isValid: false,
+		},
+		{
+			name: "empty aspect name",
+			review: func() *model.Review {
+				review := model.TestReview(t)
+				review.Aspects = map[string]int8{"": 5}
+				return review
+			},
+			isValid: false,
+		},
 		{
 			name: "empty title",
 			review: func() *model.Review {
@@ -174,6 +201,34 @@ func TestReview_Validate(t *testing.T) {
 			},
 			isValid: false,
 		},
+		{
+			name: "with subject",
+			review: func() *model.Review {
+				review := model.TestReview(t)
+				review.SubjectType = " Product "
+				review.SubjectID = "sku-42"
+				return review
+			},
+			isValid: true,
+		},
+		{
+			name: "subject type without id",
+			review: func() *model.Review {
+				review := model.TestReview(t)
+				review.SubjectType = "product"
+				return review
+			},
+			isValid: false,
+		},
+		{
+			name: "subject id without type",
+			review: func() *model.Review {
+				review := model.TestReview(t)
+				review.SubjectID = "sku-42"
+				return review
+			},
+			isValid: false,
+		},
 	}
 
 	for _, testcase := range testcases {
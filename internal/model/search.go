@@ -0,0 +1,65 @@
+package model
+
+import "github.com/leebenson/conform"
+
+const (
+	DefaultSearchLanguage = "english"
+
+	HighlightStart = "<mark>"
+	HighlightStop  = "</mark>"
+)
+
+type SearchQuery struct {
+	Query       string `json:"query" validate:"required,lte=200" conform:"trim"`
+	Language    string `json:"-" validate:"omitempty,alpha,lte=64"`
+	Limit       int    `json:"limit" validate:"gte=0,lte=100"`
+	Offset      int    `json:"offset" validate:"gte=0"`
+	Cursor      string `json:"cursor" conform:"trim"`
+	SubjectType string `json:"subject_type" validate:"required_with=SubjectID,omitempty,lte=50" conform:"trim,lower"`
+	SubjectID   string `json:"subject_id" validate:"required_with=SubjectType,omitempty,lte=100" conform:"trim"`
+	Status      string `json:"-" validate:"omitempty,oneof=pending published rejected flagged"`
+}
+
+type SearchResult struct {
+	Review
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+type SearchPage struct {
+	Results    []SearchResult `json:"results"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	Total      int            `json:"total"`
+}
+
+func (q *SearchQuery) Validate() error {
+	validate := newValidator()
+	if err := conform.Strings(q); err != nil {
+		return err
+	}
+
+	if err := validate.Struct(q); err != nil {
+		return err
+	}
+
+	if q.Cursor != "" {
+		offset, err := DecodeCursor(q.Cursor)
+		if err != nil {
+			return err
+		}
+		q.Offset = offset
+	}
+
+	if q.Limit == 0 {
+		q.Limit = DefaultPageLimit
+	}
+	if q.Language == "" {
+		q.Language = DefaultSearchLanguage
+	}
+
+	return nil
+}
+
+func (q *SearchQuery) NextCursor(count, total int) string {
+	return nextCursor(q.Offset, count, total)
+}
@@ -0,0 +1,39 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchQuery_Validate(t *testing.T) {
+	testcases := []struct {
+		name           string
+		query          *model.SearchQuery
+		expectedOffset int
+		isValid        bool
+	}{
+		{name: "valid", query: &model.SearchQuery{Query: " battery life "}, isValid: true},
+		{name: "cursor", query: &model.SearchQuery{Query: "battery", Cursor: model.EncodeCursor(20)}, expectedOffset: 20, isValid: true},
+		{name: "empty query", query: &model.SearchQuery{Query: "   "}, isValid: false},
+		{name: "invalid cursor", query: &model.SearchQuery{Query: "battery", Cursor: "!"}, isValid: false},
+		{name: "invalid language", query: &model.SearchQuery{Query: "battery", Language: "english'; --"}, isValid: false},
+		{name: "subject id without type", query: &model.SearchQuery{Query: "battery", SubjectID: "sku-42"}, isValid: false},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			err := testcase.query.Validate()
+
+			if testcase.isValid {
+				assert.NoError(t, err)
+				assert.Equal(t, model.DefaultPageLimit, testcase.query.Limit)
+				assert.Equal(t, model.DefaultSearchLanguage, testcase.query.Language)
+				assert.Equal(t, testcase.expectedOffset, testcase.query.Offset)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
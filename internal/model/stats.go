@@ -0,0 +1,62 @@
+package model
+
+const HistogramSize = 10
+
+type ReviewStats struct {
+	SubjectType     string                  `json:"subject_type"`
+	SubjectID       string                  `json:"subject_id"`
+	Count           int                     `json:"count"`
+	Sum             int                     `json:"-"`
+	Mean            float64                 `json:"mean"`
+	PriorMean       float64                 `json:"prior_mean"`
+	WeightedAverage float64                 `json:"weighted_average"`
+	Histogram       [HistogramSize]int      `json:"histogram"`
+	Aspects         map[string]*AspectStats `json:"aspects,omitempty"`
+}
+
+func (s *ReviewStats) Add(rating int8) {
+	if rating < 1 || rating > HistogramSize {
+		return
+	}
+
+	s.Count++
+	s.Sum += int(rating)
+	s.Histogram[rating-1]++
+}
+
+func (s *ReviewStats) AddAspects(aspects map[string]int8) {
+	for name, rating := range aspects {
+		if rating < 1 || rating > HistogramSize {
+			continue
+		}
+
+		if s.Aspects == nil {
+			s.Aspects = make(map[string]*AspectStats)
+		}
+		if s.Aspects[name] == nil {
+			s.Aspects[name] = &AspectStats{}
+		}
+
+		s.Aspects[name].Count++
+		s.Aspects[name].Sum += int(rating)
+	}
+}
+
+func (s *ReviewStats) Calculate(priorWeight float64) {
+	s.Mean = 0
+	if s.Count > 0 {
+		s.Mean = float64(s.Sum) / float64(s.Count)
+	}
+
+	s.WeightedAverage = 0
+	if weight := priorWeight + float64(s.Count); weight > 0 {
+		s.WeightedAverage = (priorWeight*s.PriorMean + float64(s.Sum)) / weight
+	}
+
+	for _, aspect := range s.Aspects {
+		aspect.Mean = 0
+		if aspect.Count > 0 {
+			aspect.Mean = float64(aspect.Sum) / float64(aspect.Count)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewStats_Calculate(t *testing.T) {
+	testcases := []struct {
+		name              string
+		ratings           []int8
+		priorMean         float64
+		priorWeight       float64
+		expectedMean      float64
+		expectedWeighted  float64
+		expectedHistogram [model.HistogramSize]int
+	}{
+		{
+			name:             "empty",
+			priorMean:        6,
+			priorWeight:      10,
+			expectedMean:     0,
+			expectedWeighted: 6,
+		},
+		{
+			name:              "pulled towards prior",
+			ratings:           []int8{10, 10},
+			priorMean:         5,
+			priorWeight:       2,
+			expectedMean:      10,
+			expectedWeighted:  7.5,
+			expectedHistogram: [model.HistogramSize]int{9: 2},
+		},
+		{
+			name:              "no prior weight",
+			ratings:           []int8{1, 4, 4},
+			priorMean:         8,
+			priorWeight:       0,
+			expectedMean:      3,
+			expectedWeighted:  3,
+			expectedHistogram: [model.HistogramSize]int{0: 1, 3: 2},
+		},
+		{
+			name:              "out of range ratings ignored",
+			ratings:           []int8{0, 11, 5},
+			priorWeight:       0,
+			expectedMean:      5,
+			expectedWeighted:  5,
+			expectedHistogram: [model.HistogramSize]int{4: 1},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			stats := &model.ReviewStats{PriorMean: testcase.priorMean}
+			for _, rating := range testcase.ratings {
+				stats.Add(rating)
+			}
+
+			stats.Calculate(testcase.priorWeight)
+
+			assert.InDelta(t, testcase.expectedMean, stats.Mean, 1e-9)
+			assert.InDelta(t, testcase.expectedWeighted, stats.WeightedAverage, 1e-9)
+			assert.Equal(t, testcase.expectedHistogram, stats.Histogram)
+		})
+	}
+}
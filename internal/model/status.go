@@ -0,0 +1,62 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/leebenson/conform"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusPublished = "published"
+	StatusRejected  = "rejected"
+	StatusFlagged   = "flagged"
+)
+
+var statusTransitions = map[string][]string{
+	StatusPending:   {StatusPublished, StatusRejected},
+	StatusPublished: {StatusPending, StatusFlagged, StatusRejected},
+	StatusFlagged:   {StatusPublished, StatusRejected},
+	StatusRejected:  {StatusPublished},
+}
+
+type Moderation struct {
+	ReviewID  int       `json:"id" validate:"required"`
+	Moderator string    `json:"moderator" validate:"required,lte=100" conform:"trim"`
+	Reason    string    `json:"reason" validate:"lte=500" conform:"trim"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (m *Moderation) Validate() error {
+	validate := newValidator()
+	if err := conform.Strings(m); err != nil {
+		return err
+	}
+
+	return validate.Struct(m)
+}
+
+func CanTransition(from, to string) bool {
+	for _, status := range statusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+type InvalidTransition struct {
+	from string
+	to   string
+}
+
+func NewInvalidTransition(from, to string) *InvalidTransition {
+	return &InvalidTransition{from: from, to: to}
+}
+
+func (e *InvalidTransition) Error() string {
+	return fmt.Sprintf("review cannot move from %s to %s", e.from, e.to)
+}
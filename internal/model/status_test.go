@@ -0,0 +1,68 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransition(t *testing.T) {
+	testcases := []struct {
+		name    string
+		from    string
+		to      string
+		allowed bool
+	}{
+		{name: "approve pending", from: model.StatusPending, to: model.StatusPublished, allowed: true},
+		{name: "reject pending", from: model.StatusPending, to: model.StatusRejected, allowed: true},
+		{name: "flag published", from: model.StatusPublished, to: model.StatusFlagged, allowed: true},
+		{name: "edit published", from: model.StatusPublished, to: model.StatusPending, allowed: true},
+		{name: "approve flagged", from: model.StatusFlagged, to: model.StatusPublished, allowed: true},
+		{name: "reinstate rejected", from: model.StatusRejected, to: model.StatusPublished, allowed: true},
+		{name: "flag pending", from: model.StatusPending, to: model.StatusFlagged, allowed: false},
+		{name: "approve published", from: model.StatusPublished, to: model.StatusPublished, allowed: false},
+		{name: "back to pending", from: model.StatusRejected, to: model.StatusPending, allowed: false},
+		{name: "unknown status", from: "archived", to: model.StatusPublished, allowed: false},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			assert.Equal(t, testcase.allowed, model.CanTransition(testcase.from, testcase.to))
+		})
+	}
+}
+
+func TestModeration_Validate(t *testing.T) {
+	testcases := []struct {
+		name       string
+		moderation *model.Moderation
+		isValid    bool
+	}{
+		{
+			name:       "valid",
+			moderation: &model.Moderation{ReviewID: 1, Moderator: "moderator@example.com", Reason: "spam"},
+			isValid:    true,
+		},
+		{
+			name:       "missing review",
+			moderation: &model.Moderation{Moderator: "moderator@example.com"},
+			isValid:    false,
+		},
+		{
+			name:       "whitespace moderator",
+			moderation: &model.Moderation{ReviewID: 1, Moderator: "   "},
+			isValid:    false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			if testcase.isValid {
+				assert.NoError(t, testcase.moderation.Validate())
+			} else {
+				assert.Error(t, testcase.moderation.Validate())
+			}
+		})
+	}
+}
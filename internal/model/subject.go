@@ -0,0 +1,22 @@
+package model
+
+import "github.com/leebenson/conform"
+
+type Subject struct {
+	Type string `json:"subject_type" validate:"required,lte=50" conform:"trim,lower"`
+	ID   string `json:"subject_id" validate:"required,lte=100" conform:"trim"`
+}
+
+type SubjectCount struct {
+	Subject
+	Count int `json:"count"`
+}
+
+func (s *Subject) Validate() error {
+	validate := newValidator()
+	if err := conform.Strings(s); err != nil {
+		return err
+	}
+
+	return validate.Struct(s)
+}
@@ -12,3 +12,35 @@ func TestReview(t *testing.T) *Review {
 		Description: "Description of the review",
 	}
 }
+
+func TestReport(t *testing.T, reviewID int) *Report {
+	t.Helper()
+
+	return &Report{
+		ReviewID: reviewID,
+		Reporter: "reporter@example.com",
+		Reason:   "spam",
+		Note:     "Links to a scam website",
+	}
+}
+
+func TestVote(t *testing.T, reviewID int, helpful bool) *Vote {
+	t.Helper()
+
+	return &Vote{
+		ReviewID: reviewID,
+		Voter:    "voter@example.com",
+		Helpful:  &helpful,
+	}
+}
+
+func TestReply(t *testing.T, reviewID int) *Reply {
+	t.Helper()
+
+	return &Reply{
+		ReviewID: reviewID,
+		Author:   "support@example.com",
+		Role:     RoleMerchant,
+		Body:     "Thank you for the feedback",
+	}
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"math"
+	"time"
+
+	"github.com/leebenson/conform"
+)
+
+const wilsonZ = 1.96
+
+type Vote struct {
+	ReviewID  int       `json:"review_id" validate:"required"`
+	Voter     string    `json:"voter" validate:"required,lte=100" conform:"trim"`
+	Helpful   *bool     `json:"helpful" validate:"required"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (v *Vote) Validate() error {
+	validate := newValidator()
+	if err := conform.Strings(v); err != nil {
+		return err
+	}
+
+	return validate.Struct(v)
+}
+
+func (r *Review) Helpfulness() float64 {
+	return WilsonScore(r.HelpfulVotes, r.UnhelpfulVotes)
+}
+
+func WilsonScore(positive, negative int) float64 {
+	n := float64(positive + negative)
+	if n == 0 {
+		return 0
+	}
+
+	phat := float64(positive) / n
+	z2 := wilsonZ * wilsonZ
+
+	return (phat + z2/(2*n) - wilsonZ*math.Sqrt((phat*(1-phat)+z2/(4*n))/n)) / (1 + z2/n)
+}
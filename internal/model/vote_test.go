@@ -0,0 +1,77 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVote_Validate(t *testing.T) {
+	testcases := []struct {
+		name    string
+		vote    func() *model.Vote
+		isValid bool
+	}{
+		{
+			name: "helpful",
+			vote: func() *model.Vote {
+				return model.TestVote(t, 1, true)
+			},
+			isValid: true,
+		},
+		{
+			name: "unhelpful",
+			vote: func() *model.Vote {
+				return model.TestVote(t, 1, false)
+			},
+			isValid: true,
+		},
+		{
+			name: "empty review id",
+			vote: func() *model.Vote {
+				return model.TestVote(t, 0, true)
+			},
+			isValid: false,
+		},
+		{
+			name: "empty voter",
+			vote: func() *model.Vote {
+				vote := model.TestVote(t, 1, true)
+				vote.Voter = " "
+				return vote
+			},
+			isValid: false,
+		},
+		{
+			name: "missing helpful",
+			vote: func() *model.Vote {
+				vote := model.TestVote(t, 1, true)
+				vote.Helpful = nil
+				return vote
+			},
+			isValid: false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			if testcase.isValid {
+				assert.NoError(t, testcase.vote().Validate())
+			} else {
+				assert.Error(t, testcase.vote().Validate())
+			}
+		})
+	}
+}
+
+func TestWilsonScore(t *testing.T) {
+	assert.Zero(t, model.WilsonScore(0, 0))
+	assert.Zero(t, model.WilsonScore(0, 5))
+	assert.InDelta(t, 0.2065, model.WilsonScore(1, 0), 0.0001)
+	assert.InDelta(t, 0.4171, model.WilsonScore(10, 5), 0.0001)
+
+	assert.Greater(t, model.WilsonScore(100, 10), model.WilsonScore(5, 0))
+	assert.Greater(t, model.WilsonScore(10, 1), model.WilsonScore(10, 5))
+	assert.Less(t, model.WilsonScore(1000, 0), 1.0)
+}
@@ -0,0 +1,48 @@
+package rabbitmq
+
+import (
+	"context"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type Connection interface {
+	Channel() (Channel, error)
+	NotifyClose(chan *amqp.Error) chan *amqp.Error
+	IsClosed() bool
+	Close() error
+}
+
+type Channel interface {
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	Cancel(consumer string, noWait bool) error
+	Get(queue string, autoAck bool) (amqp.Delivery, bool, error)
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	PublishWithDeferredConfirmWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) (*amqp.DeferredConfirmation, error)
+	Confirm(noWait bool) error
+	IsClosed() bool
+	Close() error
+}
+
+type Dialer func(address string) (Connection, error)
+
+type connection struct {
+	*amqp.Connection
+}
+
+func Dial(address string) (Connection, error) {
+	conn, err := amqp.Dial(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &connection{conn}, nil
+}
+
+func (c *connection) Channel() (Channel, error) {
+	return c.Connection.Channel()
+}
@@ -0,0 +1,99 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var ErrNotConfirmed = errors.New("event was not confirmed by the broker")
+
+type EventSink struct {
+	rmq      *Rabbitmq
+	exchange string
+	mu       sync.Mutex
+	channel  Channel
+}
+
+func (rmq *Rabbitmq) EventSink(exchange string) (*EventSink, error) {
+	sink := &EventSink{
+		rmq:      rmq,
+		exchange: exchange,
+	}
+
+	if _, err := sink.open(); err != nil {
+		return nil, err
+	}
+
+	return sink, nil
+}
+
+func (s *EventSink) Send(event *model.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channel, err := s.open()
+	if err != nil {
+		return err
+	}
+
+	confirmation, err := channel.PublishWithDeferredConfirmWithContext(
+		context.Background(),
+		s.exchange,
+		event.Type,
+		false,
+		false,
+		amqp.Publishing{
+			MessageId:    event.ID,
+			Type:         event.Type,
+			AppId:        event.Source,
+			Timestamp:    event.OccurredAt,
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         body,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if !confirmation.Wait() {
+		return ErrNotConfirmed
+	}
+
+	return nil
+}
+
+func (s *EventSink) open() (Channel, error) {
+	if s.channel != nil && !s.channel.IsClosed() {
+		return s.channel, nil
+	}
+
+	channel, err := s.rmq.connection().Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := channel.ExchangeDeclare(s.exchange, "topic", true, false, false, false, nil); err != nil {
+		channel.Close()
+		return nil, err
+	}
+
+	if err := channel.Confirm(false); err != nil {
+		channel.Close()
+		return nil, err
+	}
+
+	s.channel = channel
+
+	return channel, nil
+}
@@ -1,47 +1,253 @@
 package rabbitmq
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"sync"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+var (
+	ErrDisconnected = errors.New("rabbitmq connection is not available")
+	ErrClosed       = errors.New("rabbitmq connection is closed")
+	ErrCancelled    = errors.New("rabbitmq consumer is cancelled")
+)
+
+type State int32
+
+const (
+	StateDisconnected State = iota
+	StateConnected
+	StateReconnecting
+	StateClosed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateClosed:
+		return "closed"
+	default:
+		return "disconnected"
+	}
+}
+
+type ReconnectConfig struct {
+	InitialDelay time.Duration `toml:"initial_delay"`
+	MaxDelay     time.Duration `toml:"max_delay"`
+}
+
+func NewReconnectConfig() *ReconnectConfig {
+	return &ReconnectConfig{
+		InitialDelay: time.Second,
+		MaxDelay:     30 * time.Second,
+	}
+}
+
 type Rabbitmq struct {
-	Connection *amqp.Connection
-	Channel    *amqp.Channel
+	Connection Connection
+	Channel    Channel
+	Retry      *RetryConfig
+	Reconnect  *ReconnectConfig
+	Prefetch   int
+
+	mu       sync.RWMutex
+	dial     Dialer
+	address  string
+	patterns []string
+	state    State
+
+	confirmMu      sync.Mutex
+	confirmChannel Channel
+
+	done       chan struct{}
+	once       sync.Once
+	cancel     chan struct{}
+	cancelOnce sync.Once
 }
 
 func New() *Rabbitmq {
-	return &Rabbitmq{}
+	return &Rabbitmq{
+		Retry:     NewRetryConfig(),
+		Reconnect: NewReconnectConfig(),
+		Prefetch:  1,
+		dial:      Dial,
+		done:      make(chan struct{}),
+		cancel:    make(chan struct{}),
+	}
+}
+
+func (rmq *Rabbitmq) SetDialer(dial Dialer) {
+	rmq.dial = dial
 }
 
 func (rmq *Rabbitmq) Connect(user string, password string, host string, port string) error {
-	address := fmt.Sprintf("amqp://%s:%s@%s:%s/", user, password, host, port)
+	rmq.address = fmt.Sprintf("amqp://%s:%s@%s:%s/", user, password, host, port)
+
+	return rmq.connect()
+}
 
-	connection, err := amqp.Dial(address)
+func (rmq *Rabbitmq) connect() error {
+	connection, err := rmq.dial(rmq.address)
 	if err != nil {
 		return err
 	}
 
 	channel, err := connection.Channel()
 	if err != nil {
+		connection.Close()
 		return err
 	}
 
-	if err := channel.ExchangeDeclare("reviews", "topic", true, false, false, false, nil); err != nil {
+	if err := channel.ExchangeDeclare(exchangeName, "topic", true, false, false, false, nil); err != nil {
+		connection.Close()
 		return err
 	}
 
-	if err := channel.Qos(1, 0, false); err != nil {
+	if err := channel.Qos(rmq.Prefetch, 0, false); err != nil {
+		connection.Close()
 		return err
 	}
 
+	rmq.mu.Lock()
+	if rmq.state == StateClosed {
+		rmq.mu.Unlock()
+		connection.Close()
+		return ErrClosed
+	}
 	rmq.Connection = connection
 	rmq.Channel = channel
+	rmq.state = StateConnected
+	rmq.mu.Unlock()
+
+	go rmq.watch(connection, connection.NotifyClose(make(chan *amqp.Error, 1)))
 
 	return nil
 }
 
+func (rmq *Rabbitmq) watch(connection Connection, notify chan *amqp.Error) {
+	reason, ok := <-notify
+	if !ok {
+		return
+	}
+
+	log.Printf("RabbitMQ connection lost: %s", reason)
+
+	rmq.mu.Lock()
+	if rmq.Connection == connection && rmq.state == StateConnected {
+		rmq.state = StateDisconnected
+	}
+	rmq.mu.Unlock()
+}
+
+func (rmq *Rabbitmq) reconnect() (<-chan amqp.Delivery, error) {
+	rmq.setState(StateReconnecting)
+
+	if connection := rmq.connection(); connection != nil && !connection.IsClosed() {
+		connection.Close()
+	}
+
+	for attempt := 1; ; attempt++ {
+		delay := backoff(rmq.Reconnect.InitialDelay, rmq.Reconnect.MaxDelay, attempt)
+		log.Printf("reconnecting to RabbitMQ in %s (attempt %d)", delay, attempt)
+
+		select {
+		case <-rmq.done:
+			return nil, ErrClosed
+		case <-rmq.cancel:
+			return nil, ErrCancelled
+		case <-time.After(delay):
+		}
+
+		if err := rmq.connect(); err != nil {
+			log.Printf("failed to reconnect to RabbitMQ: %s", err)
+			rmq.setState(StateReconnecting)
+			continue
+		}
+
+		deliveries, err := rmq.subscribe()
+		if err != nil {
+			log.Printf("failed to resume consuming: %s", err)
+			rmq.connection().Close()
+			rmq.setState(StateReconnecting)
+			continue
+		}
+
+		log.Printf("reconnected to RabbitMQ")
+
+		return deliveries, nil
+	}
+}
+
+func (rmq *Rabbitmq) connection() Connection {
+	rmq.mu.RLock()
+	defer rmq.mu.RUnlock()
+
+	return rmq.Connection
+}
+
+func (rmq *Rabbitmq) channel() Channel {
+	rmq.mu.RLock()
+	defer rmq.mu.RUnlock()
+
+	return rmq.Channel
+}
+
+func (rmq *Rabbitmq) setState(state State) {
+	rmq.mu.Lock()
+	defer rmq.mu.Unlock()
+
+	if rmq.state != StateClosed {
+		rmq.state = state
+	}
+}
+
+func (rmq *Rabbitmq) State() State {
+	rmq.mu.RLock()
+	defer rmq.mu.RUnlock()
+
+	return rmq.state
+}
+
+func (rmq *Rabbitmq) Health() error {
+	switch rmq.State() {
+	case StateConnected:
+		return nil
+	case StateClosed:
+		return ErrClosed
+	default:
+		return ErrDisconnected
+	}
+}
+
+func (rmq *Rabbitmq) stopped() bool {
+	select {
+	case <-rmq.done:
+		return true
+	case <-rmq.cancel:
+		return true
+	default:
+		return false
+	}
+}
+
 func (rmq *Rabbitmq) Close() {
-	rmq.Connection.Close()
+	rmq.once.Do(func() {
+		close(rmq.done)
+	})
+
+	rmq.mu.Lock()
+	rmq.state = StateClosed
+	connection := rmq.Connection
+	rmq.mu.Unlock()
+
+	if connection != nil {
+		connection.Close()
+	}
 }
@@ -0,0 +1,309 @@
+package rabbitmq_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/rabbitmq"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeConnection struct {
+	mu       sync.Mutex
+	channel  *fakeChannel
+	notifies []chan *amqp.Error
+	closed   bool
+}
+
+func newFakeConnection() *fakeConnection {
+	return &fakeConnection{
+		channel: &fakeChannel{deliveries: make(chan amqp.Delivery)},
+	}
+}
+
+func (c *fakeConnection) Channel() (rabbitmq.Channel, error) {
+	return c.channel, nil
+}
+
+func (c *fakeConnection) NotifyClose(receiver chan *amqp.Error) chan *amqp.Error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		close(receiver)
+	} else {
+		c.notifies = append(c.notifies, receiver)
+	}
+
+	return receiver
+}
+
+func (c *fakeConnection) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closed
+}
+
+func (c *fakeConnection) Close() error {
+	c.shutdown(nil)
+	return nil
+}
+
+func (c *fakeConnection) Drop() {
+	c.shutdown(&amqp.Error{Code: amqp.ConnectionForced, Reason: "broker restarted"})
+}
+
+func (c *fakeConnection) shutdown(reason *amqp.Error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.closed = true
+
+	for _, receiver := range c.notifies {
+		if reason != nil {
+			receiver <- reason
+		}
+		close(receiver)
+	}
+	c.channel.Close()
+}
+
+type fakeChannel struct {
+	mu         sync.Mutex
+	deliveries chan amqp.Delivery
+	bindings   []string
+	queues     map[string]amqp.Table
+	closed     bool
+}
+
+func (c *fakeChannel) ExchangeDeclare(string, string, bool, bool, bool, bool, amqp.Table) error {
+	return nil
+}
+
+func (c *fakeChannel) QueueDeclare(name string, _, _, _, _ bool, args amqp.Table) (amqp.Queue, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.queues == nil {
+		c.queues = make(map[string]amqp.Table)
+	}
+	c.queues[name] = args
+
+	return amqp.Queue{Name: name}, nil
+}
+
+func (c *fakeChannel) QueueBind(name, key, exchange string, _ bool, _ amqp.Table) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.bindings = append(c.bindings, exchange+":"+key)
+
+	return nil
+}
+
+func (c *fakeChannel) Qos(int, int, bool) error {
+	return nil
+}
+
+func (c *fakeChannel) Consume(string, string, bool, bool, bool, bool, amqp.Table) (<-chan amqp.Delivery, error) {
+	return c.deliveries, nil
+}
+
+func (c *fakeChannel) Cancel(string, bool) error {
+	return c.Close()
+}
+
+func (c *fakeChannel) Get(string, bool) (amqp.Delivery, bool, error) {
+	return amqp.Delivery{}, false, nil
+}
+
+func (c *fakeChannel) Publish(string, string, bool, bool, amqp.Publishing) error {
+	return nil
+}
+
+func (c *fakeChannel) PublishWithDeferredConfirmWithContext(context.Context, string, string, bool, bool, amqp.Publishing) (*amqp.DeferredConfirmation, error) {
+	return nil, nil
+}
+
+func (c *fakeChannel) Confirm(bool) error {
+	return nil
+}
+
+func (c *fakeChannel) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closed
+}
+
+func (c *fakeChannel) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.deliveries)
+	}
+
+	return nil
+}
+
+func (c *fakeChannel) Bindings() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.bindings...)
+}
+
+func (c *fakeChannel) Queues() map[string]amqp.Table {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	queues := make(map[string]amqp.Table, len(c.queues))
+	for name, args := range c.queues {
+		queues[name] = args
+	}
+
+	return queues
+}
+
+type fakeBroker struct {
+	mu          sync.Mutex
+	connections []*fakeConnection
+	failures    int
+}
+
+func (b *fakeBroker) Dial(string) (rabbitmq.Connection, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures > 0 {
+		b.failures--
+		return nil, errors.New("connection refused")
+	}
+
+	connection := newFakeConnection()
+	b.connections = append(b.connections, connection)
+
+	return connection, nil
+}
+
+func (b *fakeBroker) Fail(times int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = times
+}
+
+func (b *fakeBroker) Connection(n int) *fakeConnection {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if n >= len(b.connections) {
+		return nil
+	}
+
+	return b.connections[n]
+}
+
+func prepareReconnectTest(t *testing.T) (*rabbitmq.Rabbitmq, *fakeBroker) {
+	t.Helper()
+
+	broker := &fakeBroker{}
+
+	rmq := rabbitmq.New()
+	rmq.SetDialer(broker.Dial)
+	rmq.Reconnect = &rabbitmq.ReconnectConfig{
+		InitialDelay: time.Millisecond,
+		MaxDelay:     5 * time.Millisecond,
+	}
+
+	assert.NoError(t, rmq.Connect("guest", "guest", "localhost", "5672"))
+	assert.Equal(t, rabbitmq.StateConnected, rmq.State())
+
+	return rmq, broker
+}
+
+func TestRabbitmq_Reconnect(t *testing.T) {
+	rmq, broker := prepareReconnectTest(t)
+	defer rmq.Close()
+
+	messages, err := rmq.Consume([]string{"reviews-create"})
+	assert.NoError(t, err)
+	assert.Contains(t, broker.Connection(0).channel.Bindings(), "reviews:reviews-create")
+
+	broker.Connection(0).channel.deliveries <- amqp.Delivery{RoutingKey: "reviews-create"}
+	assert.Equal(t, "reviews-create", (<-messages).Pattern())
+
+	broker.Fail(2)
+	broker.Connection(0).Drop()
+
+	assert.Eventually(t, func() bool {
+		return broker.Connection(1) != nil && rmq.State() == rabbitmq.StateConnected
+	}, time.Second, time.Millisecond)
+	assert.NoError(t, rmq.Health())
+	assert.Contains(t, broker.Connection(1).channel.Bindings(), "reviews:reviews-create")
+
+	broker.Connection(1).channel.deliveries <- amqp.Delivery{RoutingKey: "reviews-update"}
+	assert.Equal(t, "reviews-update", (<-messages).Pattern())
+}
+
+func TestRabbitmq_Close(t *testing.T) {
+	rmq, broker := prepareReconnectTest(t)
+
+	messages, err := rmq.Consume([]string{"reviews-create"})
+	assert.NoError(t, err)
+
+	rmq.Close()
+
+	select {
+	case _, ok := <-messages:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("messages channel was not closed")
+	}
+
+	assert.Equal(t, rabbitmq.StateClosed, rmq.State())
+	assert.ErrorIs(t, rmq.Health(), rabbitmq.ErrClosed)
+	assert.Nil(t, broker.Connection(1))
+}
+
+func TestRabbitmq_Health(t *testing.T) {
+	rmq, broker := prepareReconnectTest(t)
+	defer rmq.Close()
+
+	broker.Fail(1000)
+	broker.Connection(0).Drop()
+
+	assert.Eventually(t, func() bool {
+		return errors.Is(rmq.Health(), rabbitmq.ErrDisconnected)
+	}, time.Second, time.Millisecond)
+}
+
+func TestRabbitmq_Cancel(t *testing.T) {
+	rmq, broker := prepareReconnectTest(t)
+	defer rmq.Close()
+
+	messages, err := rmq.Consume([]string{"reviews-create"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, rmq.Cancel())
+
+	select {
+	case _, ok := <-messages:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("messages channel was not closed")
+	}
+
+	assert.Nil(t, broker.Connection(1))
+	assert.Equal(t, rabbitmq.StateConnected, rmq.State())
+}
@@ -0,0 +1,83 @@
+package rabbitmq
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Restyx/golang-reviews-service/internal/transport"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func (rmq *Rabbitmq) Redrive(limit int) (int, error) {
+	channel, err := rmq.connection().Channel()
+	if err != nil {
+		return 0, err
+	}
+	defer channel.Close()
+
+	if err := channel.Confirm(false); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for limit <= 0 || count < limit {
+		msg, ok, err := channel.Get(parkingQueue, false)
+		if err != nil {
+			return count, err
+		}
+		if !ok {
+			break
+		}
+
+		if err := redrive(channel, msg); err != nil {
+			msg.Nack(false, true)
+			return count, err
+		}
+
+		if err := msg.Ack(false); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+func redrive(channel Channel, msg amqp.Delivery) error {
+	headers := amqp.Table{}
+	for key, value := range msg.Headers {
+		if key == transport.RetryCountHeader || key == retryDelayHeader || strings.HasPrefix(key, "x-death") || strings.HasPrefix(key, "x-first-death") || strings.HasPrefix(key, "x-last-death") {
+			continue
+		}
+		headers[key] = value
+	}
+
+	confirmation, err := channel.PublishWithDeferredConfirmWithContext(
+		context.Background(),
+		exchangeName,
+		msg.RoutingKey,
+		false,
+		false,
+		amqp.Publishing{
+			Headers:       headers,
+			ContentType:   msg.ContentType,
+			DeliveryMode:  amqp.Persistent,
+			CorrelationId: msg.CorrelationId,
+			Expiration:    msg.Expiration,
+			ReplyTo:       msg.ReplyTo,
+			MessageId:     msg.MessageId,
+			Timestamp:     msg.Timestamp,
+			Type:          msg.Type,
+			Body:          msg.Body,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if !confirmation.Wait() {
+		return ErrNotConfirmed
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/transport"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	deadLetterExchange = "reviews.dlx"
+	retryExchange      = "reviews.retry"
+	parkingQueue       = "reviews_parking"
+	retryDelayHeader   = "x-retry-delay"
+)
+
+type RetryConfig struct {
+	MaxRetries   int           `toml:"max_retries"`
+	InitialDelay time.Duration `toml:"initial_delay"`
+	MaxDelay     time.Duration `toml:"max_delay"`
+}
+
+func NewRetryConfig() *RetryConfig {
+	return &RetryConfig{
+		MaxRetries:   5,
+		InitialDelay: time.Second,
+		MaxDelay:     time.Minute,
+	}
+}
+
+func (c *RetryConfig) Delay(attempt int) time.Duration {
+	return backoff(c.InitialDelay, c.MaxDelay, attempt)
+}
+
+func backoff(initial, max time.Duration, attempt int) time.Duration {
+	delay := initial
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+
+	return min(delay, max)
+}
+
+// Retry queues are named after their delay, so changing the retry config
+// declares new queues instead of redeclaring existing ones with a different
+// x-message-ttl.
+func retryQueueName(delay time.Duration) string {
+	return fmt.Sprintf("reviews_retry_%s", delay)
+}
+
+func RetryCount(headers amqp.Table) int {
+	switch count := headers[transport.RetryCountHeader].(type) {
+	case int:
+		return count
+	case int16:
+		return int(count)
+	case int32:
+		return int(count)
+	case int64:
+		return int(count)
+	default:
+		return 0
+	}
+}
+
+func (rmq *Rabbitmq) declareTopology() error {
+	channel := rmq.channel()
+
+	if err := channel.ExchangeDeclare(deadLetterExchange, "topic", true, false, false, false, nil); err != nil {
+		return err
+	}
+
+	if _, err := channel.QueueDeclare(parkingQueue, true, false, false, false, nil); err != nil {
+		return err
+	}
+
+	if err := channel.QueueBind(parkingQueue, "#", deadLetterExchange, false, nil); err != nil {
+		return err
+	}
+
+	if err := channel.ExchangeDeclare(retryExchange, "headers", true, false, false, false, nil); err != nil {
+		return err
+	}
+
+	declared := make(map[time.Duration]bool)
+	for attempt := 1; attempt <= rmq.Retry.MaxRetries; attempt++ {
+		delay := rmq.Retry.Delay(attempt)
+		if declared[delay] {
+			continue
+		}
+		declared[delay] = true
+
+		queue := retryQueueName(delay)
+
+		args := amqp.Table{
+			"x-message-ttl":          delay.Milliseconds(),
+			"x-dead-letter-exchange": exchangeName,
+		}
+		if _, err := channel.QueueDeclare(queue, true, false, false, false, args); err != nil {
+			return err
+		}
+
+		binding := amqp.Table{
+			"x-match":        "all",
+			retryDelayHeader: delay.String(),
+		}
+		if err := channel.QueueBind(queue, "", retryExchange, false, binding); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *delivery) Retry() error {
+	attempt := RetryCount(d.delivery.Headers) + 1
+	if attempt > d.rmq.Retry.MaxRetries {
+		return transport.ErrRetriesExhausted
+	}
+
+	headers := amqp.Table{}
+	for key, value := range d.delivery.Headers {
+		headers[key] = value
+	}
+	headers[transport.RetryCountHeader] = int32(attempt)
+	headers[retryDelayHeader] = d.rmq.Retry.Delay(attempt).String()
+
+	if err := d.rmq.publishConfirmed(retryExchange, d.delivery.RoutingKey, d.republish(headers)); err != nil {
+		return err
+	}
+
+	return d.delivery.Ack(false)
+}
+
+func (d *delivery) republish(headers amqp.Table) amqp.Publishing {
+	return amqp.Publishing{
+		Headers:       headers,
+		ContentType:   d.delivery.ContentType,
+		DeliveryMode:  amqp.Persistent,
+		CorrelationId: d.delivery.CorrelationId,
+		Expiration:    d.delivery.Expiration,
+		ReplyTo:       d.delivery.ReplyTo,
+		MessageId:     d.delivery.MessageId,
+		Timestamp:     d.delivery.Timestamp,
+		Type:          d.delivery.Type,
+		Body:          d.delivery.Body,
+	}
+}
+
+func (rmq *Rabbitmq) publishConfirmed(exchange, key string, msg amqp.Publishing) error {
+	rmq.confirmMu.Lock()
+	defer rmq.confirmMu.Unlock()
+
+	if rmq.confirmChannel == nil || rmq.confirmChannel.IsClosed() {
+		connection := rmq.connection()
+		if connection == nil {
+			return ErrDisconnected
+		}
+
+		channel, err := connection.Channel()
+		if err != nil {
+			return err
+		}
+
+		if err := channel.Confirm(false); err != nil {
+			channel.Close()
+			return err
+		}
+
+		rmq.confirmChannel = channel
+	}
+
+	confirmation, err := rmq.confirmChannel.PublishWithDeferredConfirmWithContext(context.Background(), exchange, key, false, false, msg)
+	if err != nil {
+		return err
+	}
+
+	if !confirmation.Wait() {
+		return ErrNotConfirmed
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package rabbitmq_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/rabbitmq"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryConfig_Delay(t *testing.T) {
+	config := &rabbitmq.RetryConfig{
+		MaxRetries:   6,
+		InitialDelay: time.Second,
+		MaxDelay:     10 * time.Second,
+	}
+
+	assert.Equal(t, time.Second, config.Delay(1))
+	assert.Equal(t, 2*time.Second, config.Delay(2))
+	assert.Equal(t, 4*time.Second, config.Delay(3))
+	assert.Equal(t, 8*time.Second, config.Delay(4))
+	assert.Equal(t, 10*time.Second, config.Delay(5))
+	assert.Equal(t, 10*time.Second, config.Delay(6))
+}
+
+func TestRetryCount(t *testing.T) {
+	assert.Equal(t, 0, rabbitmq.RetryCount(nil))
+	assert.Equal(t, 0, rabbitmq.RetryCount(amqp.Table{"x-retry-count": "2"}))
+	assert.Equal(t, 2, rabbitmq.RetryCount(amqp.Table{"x-retry-count": int32(2)}))
+	assert.Equal(t, 3, rabbitmq.RetryCount(amqp.Table{"x-retry-count": int64(3)}))
+}
+
+func TestRabbitmq_RetryTopology(t *testing.T) {
+	rmq, broker := prepareReconnectTest(t)
+	defer rmq.Close()
+
+	rmq.Retry = &rabbitmq.RetryConfig{
+		MaxRetries:   4,
+		InitialDelay: time.Second,
+		MaxDelay:     2 * time.Second,
+	}
+
+	_, err := rmq.Consume([]string{"reviews-create"})
+	assert.NoError(t, err)
+
+	queues := broker.Connection(0).channel.Queues()
+	assert.Nil(t, queues["reviews_queue"])
+	assert.Len(t, queues, 4)
+	assert.Equal(t, amqp.Table{"x-message-ttl": int64(1000), "x-dead-letter-exchange": "reviews"}, queues["reviews_retry_1s"])
+	assert.Equal(t, amqp.Table{"x-message-ttl": int64(2000), "x-dead-letter-exchange": "reviews"}, queues["reviews_retry_2s"])
+	assert.Contains(t, queues, "reviews_parking")
+}
@@ -0,0 +1,179 @@
+package rabbitmq
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/transport"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	exchangeName = "reviews"
+	queueName    = "reviews_queue"
+	consumerTag  = "reviews-service"
+)
+
+type delivery struct {
+	rmq        *Rabbitmq
+	delivery   amqp.Delivery
+	receivedAt time.Time
+}
+
+func (d *delivery) Pattern() string {
+	return d.delivery.RoutingKey
+}
+
+func (d *delivery) Body() []byte {
+	return d.delivery.Body
+}
+
+func (d *delivery) ReplyTo() string {
+	return d.delivery.ReplyTo
+}
+
+func (d *delivery) CorrelationID() string {
+	return d.delivery.CorrelationId
+}
+
+func (d *delivery) MessageID() string {
+	return d.delivery.MessageId
+}
+
+func (d *delivery) Headers() map[string]interface{} {
+	return d.delivery.Headers
+}
+
+func (d *delivery) Deadline() (time.Time, bool) {
+	if d.delivery.Expiration == "" {
+		return time.Time{}, false
+	}
+
+	ttl, err := strconv.ParseInt(d.delivery.Expiration, 10, 64)
+	if err != nil || ttl < 0 {
+		return time.Time{}, false
+	}
+
+	published := d.delivery.Timestamp
+	if published.IsZero() {
+		published = d.receivedAt
+	}
+
+	return published.Add(time.Duration(ttl) * time.Millisecond), true
+}
+
+func (d *delivery) Ack() error {
+	return d.delivery.Ack(false)
+}
+
+// Nack parks the message by publishing it to the dead-letter exchange, so it
+// reaches reviews_parking without any broker policy. If the publish is not
+// confirmed the message is requeued rather than dropped.
+func (d *delivery) Nack() error {
+	headers := amqp.Table{}
+	for key, value := range d.delivery.Headers {
+		headers[key] = value
+	}
+
+	if err := d.rmq.publishConfirmed(deadLetterExchange, d.delivery.RoutingKey, d.republish(headers)); err != nil {
+		d.delivery.Nack(false, true)
+		return err
+	}
+
+	return d.delivery.Ack(false)
+}
+
+func (rmq *Rabbitmq) newMessage(msg amqp.Delivery) transport.Message {
+	return &delivery{rmq: rmq, delivery: msg, receivedAt: time.Now()}
+}
+
+func (rmq *Rabbitmq) Consume(patterns []string) (<-chan transport.Message, error) {
+	rmq.mu.Lock()
+	rmq.patterns = patterns
+	rmq.mu.Unlock()
+
+	deliveries, err := rmq.subscribe()
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make(chan transport.Message)
+
+	go rmq.forward(deliveries, messages)
+
+	return messages, nil
+}
+
+func (rmq *Rabbitmq) subscribe() (<-chan amqp.Delivery, error) {
+	if err := rmq.declareTopology(); err != nil {
+		return nil, err
+	}
+
+	channel := rmq.channel()
+
+	// The queue predates the dead-letter exchange, so it is declared without
+	// arguments and rejected messages are parked by delivery.Nack instead.
+	queue, err := channel.QueueDeclare(queueName, true, false, false, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rmq.mu.RLock()
+	bindings := append([]string{queueName}, rmq.patterns...)
+	rmq.mu.RUnlock()
+
+	for _, pattern := range bindings {
+		log.Printf("Binding queue %s to exchange %s with routing key %s", queue.Name, exchangeName, pattern)
+
+		if err := channel.QueueBind(queue.Name, pattern, exchangeName, false, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return channel.Consume(queue.Name, consumerTag, false, false, false, false, nil)
+}
+
+func (rmq *Rabbitmq) Cancel() error {
+	rmq.cancelOnce.Do(func() {
+		close(rmq.cancel)
+	})
+
+	return rmq.channel().Cancel(consumerTag, false)
+}
+
+func (rmq *Rabbitmq) forward(deliveries <-chan amqp.Delivery, messages chan<- transport.Message) {
+	defer close(messages)
+
+	for {
+		for msg := range deliveries {
+			messages <- rmq.newMessage(msg)
+		}
+
+		if rmq.stopped() {
+			return
+		}
+
+		var err error
+		if deliveries, err = rmq.reconnect(); err != nil {
+			return
+		}
+	}
+}
+
+func (rmq *Rabbitmq) Reply(msg transport.Message, reply *transport.Reply) error {
+	return rmq.channel().Publish(
+		"",
+		msg.ReplyTo(),
+		false,
+		false,
+		amqp.Publishing{
+			Headers: amqp.Table{
+				"code": reply.Code,
+			},
+			CorrelationId: msg.CorrelationID(),
+			ContentType:   "application/json",
+			Body:          reply.Body,
+		},
+	)
+}
@@ -5,18 +5,12 @@ import (
 	"strings"
 )
 
-var (
-	ErrRecordNotFound = &RecordNotFound{}
-	ErrFieldMissing   = &RequiredFieldMissing{}
-)
-
 type RequiredFieldMissing struct {
 	fields []string
 }
 
-func (e *RequiredFieldMissing) AddFields(fields ...string) *RequiredFieldMissing {
-	e.fields = append(e.fields, fields...)
-	return e
+func NewFieldMissing(fields ...string) *RequiredFieldMissing {
+	return &RequiredFieldMissing{fields: fields}
 }
 
 func (e *RequiredFieldMissing) Error() string {
@@ -27,9 +21,8 @@ type RecordNotFound struct {
 	record string
 }
 
-func (e *RecordNotFound) Record(record string) *RecordNotFound {
-	e.record = record
-	return e
+func NewRecordNotFound(record string) *RecordNotFound {
+	return &RecordNotFound{record: record}
 }
 
 func (e *RecordNotFound) Error() string {
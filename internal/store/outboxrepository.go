@@ -0,0 +1,39 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+)
+
+type OutboxRepositoryI interface {
+	Process(context.Context, int, func(*model.OutboxMessage) error) (int, error)
+	Cleanup(context.Context, time.Time) (int, error)
+}
+
+func PublishInOrder(messages []model.OutboxMessage, publish func(*model.OutboxMessage) error) ([]int, error) {
+	var (
+		sent   []int
+		errs   []error
+		failed = make(map[int]bool)
+	)
+
+	for i := range messages {
+		message := &messages[i]
+		if failed[message.Event.ReviewID] {
+			continue
+		}
+
+		if err := publish(message); err != nil {
+			failed[message.Event.ReviewID] = true
+			errs = append(errs, err)
+			continue
+		}
+
+		sent = append(sent, message.ID)
+	}
+
+	return sent, errors.Join(errs...)
+}
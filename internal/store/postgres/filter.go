@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+)
+
+var reviewSortColumns = map[string]string{
+	"id":          "id",
+	"rating":      "rating",
+	"title":       "title",
+	"author":      "author",
+	"helpfulness": "review_helpfulness(helpful_votes, unhelpful_votes)",
+}
+
+func reviewFilter(query *model.ReviewQuery) (string, []interface{}) {
+	var (
+		conditions = []string{"deleted_at IS NULL"}
+		args       []interface{}
+	)
+
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if query.MinRating != 0 {
+		add("rating >= $%d", query.MinRating)
+	}
+	if query.MaxRating != 0 {
+		add("rating <= $%d", query.MaxRating)
+	}
+	if query.Author != "" {
+		add("author = $%d", query.Author)
+	}
+	if query.SubjectType != "" {
+		add("subject_type = $%d", query.SubjectType)
+		add("subject_id = $%d", query.SubjectID)
+	}
+	if query.Status != "" {
+		add("status = $%d", query.Status)
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+)
+
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func findIdempotent(ctx context.Context, db queryRower, author, key string, review *model.Review) (bool, error) {
+	sqlQuery := "SELECT " + reviewColumns + " FROM reviews WHERE id = (SELECT review_id FROM review_idempotency_keys WHERE author = $1 AND key = $2 AND expires_at > now())"
+
+	err := scanReview(db.QueryRowContext(ctx, sqlQuery, author, key), review)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func insertIdempotent(ctx context.Context, tx *sql.Tx, author, key string, reviewID int, window time.Duration) (bool, error) {
+	sqlQuery := `INSERT INTO review_idempotency_keys (author, key, review_id, expires_at) VALUES ($1, $2, $3, now() + $4 * interval '1 millisecond')
+	ON CONFLICT (author, key) DO UPDATE SET review_id = EXCLUDED.review_id, created_at = now(), expires_at = EXCLUDED.expires_at
+	WHERE review_idempotency_keys.expires_at <= now()`
+
+	res, err := tx.ExecContext(ctx, sqlQuery, author, key, reviewID, window.Milliseconds())
+	if err != nil {
+		return false, err
+	}
+
+	rowCnt, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowCnt > 0, nil
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store"
+	"github.com/lib/pq"
+)
+
+const outboxLockKey = 0x7265766965777300
+
+type OutboxRepository struct {
+	store *Store
+}
+
+func insertEvent(ctx context.Context, tx *sql.Tx, event *model.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO review_outbox (event_id, event_type, review_id, payload) VALUES ($1, $2, $3, $4)", event.ID, event.Type, event.ReviewID, payload)
+	return err
+}
+
+func (r *OutboxRepository) Process(ctx context.Context, limit int, publish func(*model.OutboxMessage) error) (int, error) {
+	tx, err := r.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", outboxLockKey).Scan(&locked); err != nil {
+		return 0, err
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	messages, err := r.pending(ctx, tx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	sent, publishErr := store.PublishInOrder(messages, publish)
+
+	if len(sent) > 0 {
+		if _, err := tx.ExecContext(ctx, "UPDATE review_outbox SET sent_at = now() WHERE id = ANY($1)", pq.Array(sent)); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(sent), publishErr
+}
+
+func (r *OutboxRepository) pending(ctx context.Context, tx *sql.Tx, limit int) ([]model.OutboxMessage, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT id, payload, created_at FROM review_outbox WHERE sent_at IS NULL ORDER BY id LIMIT $1", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]model.OutboxMessage, 0)
+	for rows.Next() {
+		message := model.OutboxMessage{}
+
+		var payload []byte
+		if err := rows.Scan(&message.ID, &payload, &message.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(payload, &message.Event); err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, message)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (r *OutboxRepository) Cleanup(ctx context.Context, sentBefore time.Time) (int, error) {
+	res, err := r.store.db.ExecContext(ctx, "DELETE FROM review_outbox WHERE sent_at < $1", sentBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	rowCnt, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowCnt), nil
+}
@@ -0,0 +1,119 @@
+package postgres_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store/postgres"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxRepository_Events(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+	store.EnableOutbox()
+
+	review := model.TestReview(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO reviews").WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, time.Now(), time.Now()))
+	mock.ExpectExec("INSERT INTO review_outbox").WithArgs(sqlmock.AnyArg(), model.EventReviewCreated, 1, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	_, err = store.Review().Create(context.Background(), review)
+	assert.NoError(t, err)
+
+	row := func(rating int8) *sqlmock.Rows {
+		return sqlmock.NewRows(reviewColumns).AddRow(1, review.Author, rating, review.Title, review.Description, "", "", model.StatusPending, 0, 0, []byte("{}"), time.Time{}, time.Time{})
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM reviews WHERE id=\\$1 AND deleted_at IS NULL FOR UPDATE").WithArgs(1).WillReturnRows(row(3))
+	mock.ExpectExec("INSERT INTO review_revisions").WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("UPDATE reviews").WillReturnRows(row(9))
+	mock.ExpectExec("INSERT INTO review_outbox").WithArgs(sqlmock.AnyArg(), model.EventReviewUpdated, 1, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.Review().Update(context.Background(), &model.Review{ID: 1, Rating: 9}))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM reviews WHERE id=\\$1 AND deleted_at IS NULL FOR UPDATE").WithArgs(1).WillReturnRows(row(9))
+	mock.ExpectExec("UPDATE reviews SET deleted_at = now()").WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO review_outbox").WithArgs(sqlmock.AnyArg(), model.EventReviewDeleted, 1, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.Review().Delete(context.Background(), 1))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM reviews WHERE id=\\$1 AND deleted_at IS NULL FOR UPDATE").WithArgs(1).WillReturnRows(sqlmock.NewRows(reviewColumns))
+	mock.ExpectRollback()
+
+	assert.Error(t, store.Review().Delete(context.Background(), 1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_Process(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	payload := func(id string, reviewID int) []byte {
+		body, err := json.Marshal(model.Event{ID: id, Type: model.EventReviewUpdated, ReviewID: reviewID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	mock.ExpectQuery("SELECT id, payload, created_at FROM review_outbox WHERE sent_at IS NULL").WithArgs(10).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "payload", "created_at"}).
+			AddRow(1, payload("a", 1), time.Now()).
+			AddRow(2, payload("b", 2), time.Now()).
+			AddRow(3, payload("c", 1), time.Now()),
+	)
+	mock.ExpectExec("UPDATE review_outbox SET sent_at = now\\(\\)").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	var published []string
+	sent, err := store.Outbox().Process(context.Background(), 10, func(message *model.OutboxMessage) error {
+		published = append(published, message.Event.ID)
+		if message.Event.ID == "b" {
+			return errors.New("broker unavailable")
+		}
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Equal(t, []string{"a", "b", "c"}, published)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+	mock.ExpectRollback()
+
+	sent, err = store.Outbox().Process(context.Background(), 10, func(*model.OutboxMessage) error { return nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	mock.ExpectExec("DELETE FROM review_outbox WHERE sent_at <").WillReturnResult(sqlmock.NewResult(0, 5))
+
+	count, err := store.Outbox().Cleanup(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 5, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,7 +2,9 @@ package postgres
 
 import (
 	"database/sql"
+	"time"
 
+	"github.com/Restyx/golang-reviews-service/internal/model"
 	"github.com/Restyx/golang-reviews-service/internal/store"
 	_ "github.com/lib/pq"
 )
@@ -10,11 +12,32 @@ import (
 type Store struct {
 	db               *sql.DB
 	reviewRepository *ReviewRepository
+	reportRepository *ReportRepository
+	replyRepository  *ReplyRepository
+	outboxRepository *OutboxRepository
+	outbox           bool
+	idempotency      time.Duration
+	searchLanguage   string
 }
 
 func New(db *sql.DB) *Store {
 	return &Store{
-		db: db,
+		db:             db,
+		searchLanguage: model.DefaultSearchLanguage,
+	}
+}
+
+func (s *Store) EnableOutbox() {
+	s.outbox = true
+}
+
+func (s *Store) EnableIdempotency(window time.Duration) {
+	s.idempotency = window
+}
+
+func (s *Store) SetSearchLanguage(language string) {
+	if language != "" {
+		s.searchLanguage = language
 	}
 }
 
@@ -27,3 +50,33 @@ func (s *Store) Review() store.ReviewRepositoryI {
 
 	return s.reviewRepository
 }
+
+func (s *Store) Report() store.ReportRepositoryI {
+	if s.reportRepository == nil {
+		s.reportRepository = &ReportRepository{
+			store: s,
+		}
+	}
+
+	return s.reportRepository
+}
+
+func (s *Store) Reply() store.ReplyRepositoryI {
+	if s.replyRepository == nil {
+		s.replyRepository = &ReplyRepository{
+			store: s,
+		}
+	}
+
+	return s.replyRepository
+}
+
+func (s *Store) Outbox() store.OutboxRepositoryI {
+	if s.outboxRepository == nil {
+		s.outboxRepository = &OutboxRepository{
+			store: s,
+		}
+	}
+
+	return s.outboxRepository
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store"
+)
+
+const replyColumns = "id, review_id, COALESCE(parent_id, 0), author, role, body, created_at, updated_at"
+
+type ReplyRepository struct {
+	store *Store
+}
+
+func scanReply(row rowScanner, reply *model.Reply) error {
+	return row.Scan(&reply.ID, &reply.ReviewID, &reply.ParentID, &reply.Author, &reply.Role, &reply.Body, &reply.CreatedAt, &reply.UpdatedAt)
+}
+
+func (r *ReplyRepository) Create(ctx context.Context, reply *model.Reply) (int, error) {
+	reply.ID = 0
+	if err := reply.Validate(); err != nil {
+		return 0, err
+	}
+
+	sqlQuery := `INSERT INTO review_replies (review_id, parent_id, author, role, body)
+	SELECT r.id, NULLIF($2, 0), $3, $4, $5 FROM reviews r
+	WHERE r.id = $1 AND r.deleted_at IS NULL
+	AND ($2 = 0 OR EXISTS (SELECT 1 FROM review_replies p WHERE p.id = $2 AND p.review_id = r.id))
+	RETURNING id, created_at, updated_at`
+
+	err := r.store.db.QueryRowContext(ctx, sqlQuery, reply.ReviewID, reply.ParentID, reply.Author, reply.Role, reply.Body).Scan(&reply.ID, &reply.CreatedAt, &reply.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = store.NewRecordNotFound(fmt.Sprint(reply.ReviewID))
+		}
+		return 0, err
+	}
+
+	return reply.ID, nil
+}
+
+func (r *ReplyRepository) Update(ctx context.Context, reply *model.Reply) error {
+	if reply.ID == 0 {
+		return store.NewFieldMissing("id")
+	}
+
+	if err := reply.Validate(); err != nil {
+		return err
+	}
+
+	sqlQuery := `UPDATE review_replies SET body = $2, updated_at = now() WHERE id = $1 RETURNING ` + replyColumns
+
+	if err := scanReply(r.store.db.QueryRowContext(ctx, sqlQuery, reply.ID, reply.Body), reply); err != nil {
+		if err == sql.ErrNoRows {
+			err = store.NewRecordNotFound(fmt.Sprint(reply.ID))
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *ReplyRepository) Delete(ctx context.Context, id int) error {
+	if id == 0 {
+		return store.NewFieldMissing("id")
+	}
+
+	res, err := r.store.db.ExecContext(ctx, "DELETE FROM review_replies WHERE id=$1", id)
+	if err != nil {
+		return err
+	}
+	rowCnt, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowCnt == 0 {
+		return store.NewRecordNotFound(fmt.Sprint(id))
+	}
+
+	return nil
+}
+
+func (r *ReplyRepository) FindByReview(ctx context.Context, reviewID int) ([]model.Reply, error) {
+	if reviewID == 0 {
+		return nil, store.NewFieldMissing("id")
+	}
+
+	var exists bool
+	if err := r.store.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM reviews WHERE id=$1 AND deleted_at IS NULL)", reviewID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, store.NewRecordNotFound(fmt.Sprint(reviewID))
+	}
+
+	replies := make([]model.Reply, 0)
+
+	rows, err := r.store.db.QueryContext(ctx, "SELECT "+replyColumns+" FROM review_replies WHERE review_id=$1 ORDER BY id", reviewID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		reply := model.Reply{}
+
+		if err := scanReply(rows, &reply); err != nil {
+			return nil, err
+		}
+
+		replies = append(replies, reply)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return replies, nil
+}
+
+func (r *ReplyRepository) FindLatest(ctx context.Context, reviewID int, role string) (*model.Reply, error) {
+	if reviewID == 0 {
+		return nil, store.NewFieldMissing("id")
+	}
+
+	reply := &model.Reply{}
+	if err := scanReply(r.store.db.QueryRowContext(ctx, "SELECT "+replyColumns+" FROM review_replies WHERE review_id=$1 AND role=$2 ORDER BY created_at DESC, id DESC LIMIT 1", reviewID, role), reply); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return reply, nil
+}
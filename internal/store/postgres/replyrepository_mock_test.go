@@ -0,0 +1,164 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store/postgres"
+	"github.com/stretchr/testify/assert"
+)
+
+var replyColumns = []string{"id", "review_id", "parent_id", "author", "role", "body", "created_at", "updated_at"}
+
+func TestReplyRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	type mockBehavior func(reply *model.Reply)
+
+	testTable := []struct {
+		name         string
+		inputReply   *model.Reply
+		mockBehavior mockBehavior
+		expectedID   int
+		expectError  bool
+	}{
+		{
+			name:       "valid",
+			inputReply: model.TestReply(t, 1),
+			mockBehavior: func(reply *model.Reply) {
+				rows := mock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(3, time.Now(), time.Now())
+				mock.ExpectQuery("INSERT INTO review_replies").WithArgs(reply.ReviewID, reply.ParentID, reply.Author, reply.Role, reply.Body).WillReturnRows(rows)
+			},
+			expectedID: 3,
+		},
+		{
+			name:       "not existing review",
+			inputReply: model.TestReply(t, 2),
+			mockBehavior: func(reply *model.Reply) {
+				mock.ExpectQuery("INSERT INTO review_replies").WithArgs(reply.ReviewID, reply.ParentID, reply.Author, reply.Role, reply.Body).WillReturnError(sql.ErrNoRows)
+			},
+			expectError: true,
+		},
+		{
+			name:         "missing review id",
+			inputReply:   model.TestReply(t, 0),
+			mockBehavior: func(reply *model.Reply) {},
+			expectError:  true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockBehavior(testcase.inputReply)
+
+			id, err := store.Reply().Create(context.Background(), testcase.inputReply)
+
+			if testcase.expectError {
+				assert.Error(t, err)
+				assert.Zero(t, id)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testcase.expectedID, id)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReplyRepository_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	rows := mock.NewRows(replyColumns).AddRow(1, 1, 0, "support@example.com", "merchant", "Updated answer", time.Now(), time.Now())
+	mock.ExpectQuery("UPDATE review_replies SET body").WithArgs(1, "Updated answer").WillReturnRows(rows)
+	mock.ExpectQuery("UPDATE review_replies SET body").WithArgs(2, "Updated answer").WillReturnError(sql.ErrNoRows)
+
+	reply := &model.Reply{ID: 1, Body: "Updated answer"}
+	assert.NoError(t, store.Reply().Update(context.Background(), reply))
+	assert.Equal(t, "support@example.com", reply.Author)
+	assert.Equal(t, model.RoleMerchant, reply.Role)
+
+	assert.Error(t, store.Reply().Update(context.Background(), &model.Reply{ID: 2, Body: "Updated answer"}))
+	assert.Error(t, store.Reply().Update(context.Background(), &model.Reply{Body: "Updated answer"}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplyRepository_Delete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	mock.ExpectExec("DELETE FROM review_replies").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM review_replies").WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, store.Reply().Delete(context.Background(), 1))
+	assert.Error(t, store.Reply().Delete(context.Background(), 2))
+	assert.Error(t, store.Reply().Delete(context.Background(), 0))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplyRepository_FindByReview(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	mock.ExpectQuery("SELECT EXISTS").WithArgs(1).WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+	rows := mock.NewRows(replyColumns).AddRow(1, 1, 0, "support@example.com", "merchant", "Thanks", time.Now(), time.Now()).AddRow(2, 1, 1, "example_mail@example.com", "customer", "You are welcome", time.Now(), time.Now())
+	mock.ExpectQuery("FROM review_replies WHERE review_id").WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery("SELECT EXISTS").WithArgs(2).WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(false))
+
+	replies, err := store.Reply().FindByReview(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, replies, 2)
+	assert.Equal(t, 1, replies[1].ParentID)
+
+	_, err = store.Reply().FindByReview(context.Background(), 2)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplyRepository_FindLatest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	rows := mock.NewRows(replyColumns).AddRow(5, 1, 0, "support@example.com", "merchant", "Thanks", time.Now(), time.Now())
+	mock.ExpectQuery("FROM review_replies WHERE review_id").WithArgs(1, model.RoleMerchant).WillReturnRows(rows)
+	mock.ExpectQuery("FROM review_replies WHERE review_id").WithArgs(2, model.RoleMerchant).WillReturnError(sql.ErrNoRows)
+
+	reply, err := store.Reply().FindLatest(context.Background(), 1, model.RoleMerchant)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, reply.ID)
+
+	reply, err = store.Reply().FindLatest(context.Background(), 2, model.RoleMerchant)
+	assert.NoError(t, err)
+	assert.Nil(t, reply)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store"
+)
+
+type ReportRepository struct {
+	store *Store
+}
+
+func (r *ReportRepository) Create(ctx context.Context, report *model.Report) (int, error) {
+	if err := report.Validate(); err != nil {
+		return 0, err
+	}
+
+	sqlQuery := `INSERT INTO review_reports (review_id, reporter, reason, note)
+	SELECT id, $2, $3, $4 FROM reviews WHERE id = $1 AND deleted_at IS NULL
+	ON CONFLICT (review_id, reporter) DO UPDATE SET reason = EXCLUDED.reason, note = EXCLUDED.note, created_at = now()
+	RETURNING id, created_at`
+
+	err := r.store.db.QueryRowContext(ctx, sqlQuery, report.ReviewID, report.Reporter, report.Reason, report.Note).Scan(&report.ID, &report.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = store.NewRecordNotFound(fmt.Sprint(report.ReviewID))
+		}
+		return 0, err
+	}
+
+	return report.ID, nil
+}
+
+func (r *ReportRepository) CountReporters(ctx context.Context, reviewID int) (int, error) {
+	if reviewID == 0 {
+		return 0, store.NewFieldMissing("review_id")
+	}
+
+	sqlQuery := `SELECT COUNT(DISTINCT reporter) FROM review_reports WHERE review_id = $1
+	AND created_at > COALESCE((SELECT MAX(created_at) FROM review_moderations WHERE review_id = $1), '-infinity')`
+
+	var count int
+	if err := r.store.db.QueryRowContext(ctx, sqlQuery, reviewID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
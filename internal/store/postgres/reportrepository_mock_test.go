@@ -0,0 +1,94 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store/postgres"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	type mockBehavior func(report *model.Report)
+
+	testTable := []struct {
+		name         string
+		inputReport  *model.Report
+		mockBehavior mockBehavior
+		expectedID   int
+		expectError  bool
+	}{
+		{
+			name:        "valid",
+			inputReport: model.TestReport(t, 1),
+			mockBehavior: func(report *model.Report) {
+				rows := mock.NewRows([]string{"id", "created_at"}).AddRow(7, time.Now())
+				mock.ExpectQuery("INSERT INTO review_reports").WithArgs(report.ReviewID, report.Reporter, report.Reason, report.Note).WillReturnRows(rows)
+			},
+			expectedID: 7,
+		},
+		{
+			name:        "not existing review",
+			inputReport: model.TestReport(t, 2),
+			mockBehavior: func(report *model.Report) {
+				mock.ExpectQuery("INSERT INTO review_reports").WithArgs(report.ReviewID, report.Reporter, report.Reason, report.Note).WillReturnError(sql.ErrNoRows)
+			},
+			expectError: true,
+		},
+		{
+			name:         "missing review id",
+			inputReport:  model.TestReport(t, 0),
+			mockBehavior: func(report *model.Report) {},
+			expectError:  true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockBehavior(testcase.inputReport)
+
+			id, err := store.Report().Create(context.Background(), testcase.inputReport)
+
+			if testcase.expectError {
+				assert.Error(t, err)
+				assert.Zero(t, id)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testcase.expectedID, id)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReportRepository_CountReporters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	mock.ExpectQuery(`SELECT COUNT\(DISTINCT reporter\) FROM review_reports WHERE review_id = \$1\s+AND created_at > COALESCE\(\(SELECT MAX\(created_at\) FROM review_moderations`).WithArgs(1).WillReturnRows(mock.NewRows([]string{"count"}).AddRow(2))
+
+	count, err := store.Report().CountReporters(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	_, err = store.Report().CountReporters(context.Background(), 0)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,34 +1,150 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Restyx/golang-reviews-service/internal/model"
 	"github.com/Restyx/golang-reviews-service/internal/store"
+	"github.com/lib/pq"
 )
 
+const reviewColumns = "id, author, rating, title, description, subject_type, subject_id, status, helpful_votes, unhelpful_votes, aspects, created_at, updated_at"
+
 type ReviewRepository struct {
 	store *Store
 }
 
-func (r *ReviewRepository) Create(review *model.Review) (int, error) {
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanReview(row rowScanner, review *model.Review) error {
+	var aspects []byte
+	if err := row.Scan(&review.ID, &review.Author, &review.Rating, &review.Title, &review.Description, &review.SubjectType, &review.SubjectID, &review.Status, &review.HelpfulVotes, &review.UnhelpfulVotes, &aspects, &review.CreatedAt, &review.UpdatedAt); err != nil {
+		return err
+	}
+
+	return decodeAspects(aspects, review)
+}
+
+func encodeAspects(aspects map[string]int8) ([]byte, error) {
+	if len(aspects) == 0 {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(aspects)
+}
+
+func decodeAspects(data []byte, review *model.Review) error {
+	review.Aspects = nil
+	if len(data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &review.Aspects); err != nil {
+		return err
+	}
+	if len(review.Aspects) == 0 {
+		review.Aspects = nil
+	}
+
+	return nil
+}
+
+func (r *ReviewRepository) Create(ctx context.Context, review *model.Review) (int, error) {
 	if err := review.Validate(); err != nil {
 		return 0, err
 	}
 
-	err := r.store.db.QueryRow("INSERT INTO reviews (author, rating, title, description) VALUES ($1, $2, $3, $4) RETURNING id", review.Author, review.Rating, review.Title, review.Description).Scan(&review.ID)
+	if review.Status == "" {
+		review.Status = model.StatusPending
+	}
+
+	aspects, err := encodeAspects(review.Aspects)
 	if err != nil {
 		return 0, err
 	}
 
+	key := store.IdempotencyKey(ctx)
+	if r.store.idempotency <= 0 {
+		key = ""
+	}
+
+	tx, err := r.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if key != "" {
+		found, err := findIdempotent(ctx, tx, review.Author, key, review)
+		if err != nil {
+			return 0, err
+		}
+		if found {
+			return review.ID, nil
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, "INSERT INTO reviews (author, rating, title, description, subject_type, subject_id, status, aspects, search_language) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, updated_at", review.Author, review.Rating, review.Title, review.Description, review.SubjectType, review.SubjectID, review.Status, aspects, r.store.searchLanguage).Scan(&review.ID, &review.CreatedAt, &review.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	if r.store.outbox {
+		if err := insertEvent(ctx, tx, model.NewEvent(model.EventReviewCreated, nil, review.Clone())); err != nil {
+			return 0, err
+		}
+	}
+
+	if key != "" {
+		inserted, err := insertIdempotent(ctx, tx, review.Author, key, review.ID, r.store.idempotency)
+		if err != nil {
+			return 0, err
+		}
+
+		if !inserted {
+			tx.Rollback()
+
+			found, err := findIdempotent(ctx, r.store.db, review.Author, key, review)
+			if err != nil {
+				return 0, err
+			}
+			if !found {
+				return 0, store.NewRecordNotFound(key)
+			}
+			return review.ID, nil
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
 	return review.ID, nil
 }
 
-func (r *ReviewRepository) FindAll() ([]model.Review, error) {
+func (r *ReviewRepository) findForUpdate(ctx context.Context, tx *sql.Tx, id int) (*model.Review, error) {
+	review := &model.Review{}
+	if err := scanReview(tx.QueryRowContext(ctx, "SELECT "+reviewColumns+" FROM reviews WHERE id=$1 AND deleted_at IS NULL FOR UPDATE", id), review); err != nil {
+		if err == sql.ErrNoRows {
+			err = store.NewRecordNotFound(fmt.Sprint(id))
+		}
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (r *ReviewRepository) FindAll(ctx context.Context) ([]model.Review, error) {
 	reviews := make([]model.Review, 0)
 
-	rows, err := r.store.db.Query("SELECT id, author, rating, title, description FROM reviews")
+	rows, err := r.store.db.QueryContext(ctx, "SELECT "+reviewColumns+" FROM reviews WHERE deleted_at IS NULL")
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +153,7 @@ func (r *ReviewRepository) FindAll() ([]model.Review, error) {
 	for rows.Next() {
 		review := model.Review{}
 
-		if err := rows.Scan(&review.ID, &review.Author, &review.Rating, &review.Title, &review.Description); err != nil {
+		if err := scanReview(rows, &review); err != nil {
 			return nil, err
 		}
 
@@ -50,15 +166,114 @@ func (r *ReviewRepository) FindAll() ([]model.Review, error) {
 	return reviews, nil
 }
 
-func (r *ReviewRepository) FindOne(id int) (*model.Review, error) {
+func (r *ReviewRepository) FindPage(ctx context.Context, query *model.ReviewQuery) (*model.ReviewPage, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	where, args := reviewFilter(query)
+
+	page := &model.ReviewPage{
+		Reviews: make([]model.Review, 0),
+	}
+
+	if err := r.store.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM reviews"+where, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	column, ok := reviewSortColumns[query.SortBy]
+	if !ok {
+		column = "id"
+	}
+	order := strings.ToUpper(query.SortOrder)
+
+	sqlQuery := fmt.Sprintf("SELECT %s FROM reviews%s ORDER BY %s %s, id %s LIMIT $%d OFFSET $%d", reviewColumns, where, column, order, order, len(args)+1, len(args)+2)
+
+	rows, err := r.store.db.QueryContext(ctx, sqlQuery, append(args, query.Limit, query.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		review := model.Review{}
+
+		if err := scanReview(rows, &review); err != nil {
+			return nil, err
+		}
+
+		page.Reviews = append(page.Reviews, review)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page.NextCursor = query.NextCursor(len(page.Reviews), page.Total)
+
+	return page, nil
+}
+
+func (r *ReviewRepository) Search(ctx context.Context, query *model.SearchQuery) (*model.SearchPage, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	where, args := reviewFilter(&model.ReviewQuery{
+		SubjectType: query.SubjectType,
+		SubjectID:   query.SubjectID,
+		Status:      query.Status,
+	})
+
+	args = append(args, query.Language, query.Query)
+	language, terms := len(args)-1, len(args)
+
+	from := fmt.Sprintf(" FROM reviews, websearch_to_tsquery($%d::regconfig, $%d) q%s AND search_vector @@ q", language, terms, where)
+
+	page := &model.SearchPage{
+		Results: make([]model.SearchResult, 0),
+	}
+
+	if err := r.store.db.QueryRowContext(ctx, "SELECT COUNT(*)"+from, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	sqlQuery := fmt.Sprintf("SELECT %s, ts_rank(search_vector, q) AS rank, ts_headline($%d::regconfig, title || ' ' || description, q, $%d)%s ORDER BY rank DESC, id ASC LIMIT $%d OFFSET $%d",
+		reviewColumns, language, len(args)+1, from, len(args)+2, len(args)+3)
+
+	rows, err := r.store.db.QueryContext(ctx, sqlQuery, append(args, headlineOptions, query.Limit, query.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		result := model.SearchResult{}
+
+		if err := scanReview(extraScanner{row: rows, extra: []interface{}{&result.Rank, &result.Snippet}}, &result.Review); err != nil {
+			return nil, err
+		}
+		result.Snippet = snippet(result.Snippet)
+
+		page.Results = append(page.Results, result)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page.NextCursor = query.NextCursor(len(page.Results), page.Total)
+
+	return page, nil
+}
+
+func (r *ReviewRepository) FindOne(ctx context.Context, id int) (*model.Review, error) {
 	if id == 0 {
-		return nil, store.ErrFieldMissing.AddFields("id")
+		return nil, store.NewFieldMissing("id")
 	}
 
 	review := &model.Review{}
-	if err := r.store.db.QueryRow("SELECT id, author, rating, title, description FROM reviews WHERE id=$1", id).Scan(&review.ID, &review.Author, &review.Rating, &review.Title, &review.Description); err != nil {
+	if err := scanReview(r.store.db.QueryRowContext(ctx, "SELECT "+reviewColumns+" FROM reviews WHERE id=$1 AND deleted_at IS NULL", id), review); err != nil {
 		if err == sql.ErrNoRows {
-			err = store.ErrRecordNotFound.Record(fmt.Sprint(id))
+			err = store.NewRecordNotFound(fmt.Sprint(id))
 		}
 		return nil, err
 	}
@@ -66,65 +281,314 @@ func (r *ReviewRepository) FindOne(id int) (*model.Review, error) {
 	return review, nil
 }
 
-func (r *ReviewRepository) Update(updateReview *model.Review) error {
+func (r *ReviewRepository) Update(ctx context.Context, updateReview *model.Review) error {
 	if updateReview.ID == 0 {
-		return store.ErrFieldMissing.AddFields("id")
+		return store.NewFieldMissing("id")
 	}
 
 	if err := updateReview.Validate(); err != nil {
 		return err
 	}
 
+	aspects, err := encodeAspects(updateReview.Aspects)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var before *model.Review
+	if r.store.outbox {
+		if before, err = r.findForUpdate(ctx, tx, updateReview.ID); err != nil {
+			return err
+		}
+	}
+
+	revisionQuery := `INSERT INTO review_revisions (review_id, author, rating, title, description, aspects, created_at)
+	SELECT id, author, rating, title, description, aspects, updated_at FROM reviews WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+
+	res, err := tx.ExecContext(ctx, revisionQuery, updateReview.ID)
+	if err != nil {
+		return err
+	}
+
+	rowCnt, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowCnt == 0 {
+		return store.NewRecordNotFound(fmt.Sprint(updateReview.ID))
+	}
+
 	sqlQuery := `UPDATE reviews
 	SET	
 	author = COALESCE(NULLIF($2, ''), author), 
 	rating = COALESCE(NULLIF($3, 0), rating), 
 	title = COALESCE(NULLIF($4, ''), title), 
-	description = COALESCE(NULLIF($5, ''), description)
+	description = COALESCE(NULLIF($5, ''), description),
+	aspects = aspects || $6::jsonb,
+	updated_at = now()
 	WHERE id = $1
-	RETURNING author, rating, title, description`
+	RETURNING ` + reviewColumns
 
-	stmt, err := r.store.db.Prepare(sqlQuery)
-	if err != nil {
+	if err := scanReview(tx.QueryRowContext(ctx, sqlQuery, updateReview.ID, updateReview.Author, updateReview.Rating, updateReview.Title, updateReview.Description, aspects), updateReview); err != nil {
 		return err
 	}
 
-	res, err := stmt.Exec(updateReview.ID, updateReview.Author, updateReview.Rating, updateReview.Title, updateReview.Description)
+	if r.store.outbox {
+		if err := insertEvent(ctx, tx, model.NewEvent(model.EventReviewUpdated, before, updateReview.Clone())); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *ReviewRepository) Delete(ctx context.Context, id int) error {
+	if id == 0 {
+		return store.NewFieldMissing("id")
+	}
+
+	tx, err := r.store.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
+	var before *model.Review
+	if r.store.outbox {
+		if before, err = r.findForUpdate(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, "UPDATE reviews SET deleted_at = now() WHERE id=$1 AND deleted_at IS NULL", id)
+	if err != nil {
+		return err
+	}
 	rowCnt, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
 	if rowCnt == 0 {
-		return store.ErrRecordNotFound.Record(fmt.Sprint(updateReview.ID))
+		return store.NewRecordNotFound(fmt.Sprint(id))
 	}
 
-	return nil
+	if r.store.outbox {
+		if err := insertEvent(ctx, tx, model.NewEvent(model.EventReviewDeleted, before, nil)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
-func (r *ReviewRepository) Delete(id int) error {
+func (r *ReviewRepository) Restore(ctx context.Context, id int) error {
 	if id == 0 {
-		return store.ErrFieldMissing.AddFields("id")
+		return store.NewFieldMissing("id")
 	}
 
-	stmt, err := r.store.db.Prepare("DELETE FROM reviews WHERE id=$1")
+	res, err := r.store.db.ExecContext(ctx, "UPDATE reviews SET deleted_at = NULL WHERE id=$1 AND deleted_at IS NOT NULL", id)
 	if err != nil {
 		return err
 	}
-	res, err := stmt.Exec(id)
+	rowCnt, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
+	if rowCnt == 0 {
+		return store.NewRecordNotFound(fmt.Sprint(id))
+	}
+
+	return nil
+}
+
+func (r *ReviewRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	res, err := r.store.db.ExecContext(ctx, "DELETE FROM reviews WHERE deleted_at < $1", deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	if r.store.idempotency > 0 {
+		if _, err := r.store.db.ExecContext(ctx, "DELETE FROM review_idempotency_keys WHERE expires_at <= now()"); err != nil {
+			return 0, err
+		}
+	}
+
 	rowCnt, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowCnt), nil
+}
+
+func (r *ReviewRepository) SetStatus(ctx context.Context, moderation *model.Moderation) error {
+	if moderation.ReviewID == 0 {
+		return store.NewFieldMissing("id")
+	}
+
+	tx, err := r.store.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	if rowCnt == 0 {
-		return store.ErrRecordNotFound.Record(fmt.Sprint(id))
+	defer tx.Rollback()
+
+	var status string
+	if err := tx.QueryRowContext(ctx, "SELECT status FROM reviews WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", moderation.ReviewID).Scan(&status); err != nil {
+		if err == sql.ErrNoRows {
+			err = store.NewRecordNotFound(fmt.Sprint(moderation.ReviewID))
+		}
+		return err
+	}
+
+	if !model.CanTransition(status, moderation.Status) {
+		return model.NewInvalidTransition(status, moderation.Status)
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE reviews SET status = $2 WHERE id = $1", moderation.ReviewID, moderation.Status); err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, "INSERT INTO review_moderations (review_id, status, moderator, reason) VALUES ($1, $2, $3, $4) RETURNING created_at", moderation.ReviewID, moderation.Status, moderation.Moderator, moderation.Reason).Scan(&moderation.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ReviewRepository) Vote(ctx context.Context, vote *model.Vote) error {
+	if err := vote.Validate(); err != nil {
+		return err
+	}
+
+	sqlQuery := `INSERT INTO review_votes (review_id, voter, helpful)
+	SELECT id, $2, $3 FROM reviews WHERE id = $1 AND deleted_at IS NULL
+	ON CONFLICT (review_id, voter) DO UPDATE SET helpful = EXCLUDED.helpful, created_at = now()
+	RETURNING created_at`
+
+	if err := r.store.db.QueryRowContext(ctx, sqlQuery, vote.ReviewID, vote.Voter, *vote.Helpful).Scan(&vote.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			err = store.NewRecordNotFound(fmt.Sprint(vote.ReviewID))
+		}
+		return err
 	}
 
 	return nil
 }
+
+func (r *ReviewRepository) CountBySubject(ctx context.Context, subject *model.Subject) (int, error) {
+	if err := subject.Validate(); err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := r.store.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM reviews WHERE subject_type=$1 AND subject_id=$2 AND status=$3 AND deleted_at IS NULL", subject.Type, subject.ID, model.StatusPublished).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *ReviewRepository) Stats(ctx context.Context, subject *model.Subject) (*model.ReviewStats, error) {
+	if err := subject.Validate(); err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `SELECT COALESCE(s.rating_count, 0), COALESCE(s.rating_sum, 0), COALESCE(s.histogram, array_fill(0, ARRAY[10])), g.prior_mean
+	FROM (SELECT COALESCE(SUM(rating_sum)::float8 / NULLIF(SUM(rating_count), 0), 0) AS prior_mean FROM review_stats) g
+	LEFT JOIN review_stats s ON s.subject_type = $1 AND s.subject_id = $2`
+
+	stats := &model.ReviewStats{
+		SubjectType: subject.Type,
+		SubjectID:   subject.ID,
+	}
+
+	var histogram []int64
+	if err := r.store.db.QueryRowContext(ctx, sqlQuery, subject.Type, subject.ID).Scan(&stats.Count, &stats.Sum, pq.Array(&histogram), &stats.PriorMean); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(histogram) && i < model.HistogramSize; i++ {
+		stats.Histogram[i] = int(histogram[i])
+	}
+
+	aspectQuery := `SELECT a.key, COUNT(*), SUM(a.value::int)
+	FROM reviews r, jsonb_each_text(r.aspects) a
+	WHERE r.subject_type = $1 AND r.subject_id = $2 AND r.status = $3 AND r.deleted_at IS NULL
+	GROUP BY a.key`
+
+	rows, err := r.store.db.QueryContext(ctx, aspectQuery, subject.Type, subject.ID, model.StatusPublished)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		aspect := &model.AspectStats{}
+
+		if err := rows.Scan(&name, &aspect.Count, &aspect.Sum); err != nil {
+			return nil, err
+		}
+
+		if stats.Aspects == nil {
+			stats.Aspects = make(map[string]*model.AspectStats)
+		}
+		stats.Aspects[name] = aspect
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (r *ReviewRepository) FindRevisions(ctx context.Context, id int) ([]model.ReviewRevision, error) {
+	if id == 0 {
+		return nil, store.NewFieldMissing("id")
+	}
+
+	var exists bool
+	if err := r.store.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM reviews WHERE id=$1 AND deleted_at IS NULL)", id).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, store.NewRecordNotFound(fmt.Sprint(id))
+	}
+
+	revisions := make([]model.ReviewRevision, 0)
+
+	rows, err := r.store.db.QueryContext(ctx, "SELECT id, review_id, author, rating, title, description, aspects, created_at, revised_at FROM review_revisions WHERE review_id=$1 ORDER BY id", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		revision := model.ReviewRevision{}
+		var aspects []byte
+
+		if err := rows.Scan(&revision.ID, &revision.ReviewID, &revision.Author, &revision.Rating, &revision.Title, &revision.Description, &aspects, &revision.CreatedAt, &revision.RevisedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(aspects, &revision.Aspects); err != nil {
+			return nil, err
+		}
+		if len(revision.Aspects) == 0 {
+			revision.Aspects = nil
+		}
+
+		revisions = append(revisions, revision)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
@@ -1,15 +1,21 @@
 package postgres_test
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store"
 	"github.com/Restyx/golang-reviews-service/internal/store/postgres"
 	"github.com/stretchr/testify/assert"
 )
 
+var reviewColumns = []string{"id", "author", "rating", "title", "description", "subject_type", "subject_id", "status", "helpful_votes", "unhelpful_votes", "aspects", "created_at", "updated_at"}
+
 func TestReviewRepository_Create(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -37,11 +43,31 @@ func TestReviewRepository_Create(t *testing.T) {
 				Description: "Description of the review",
 			},
 			mockBehavior: func(review *model.Review) {
-				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
-				mock.ExpectQuery("INSERT INTO reviews").WithArgs(review.Author, review.Rating, review.Title, review.Description).WillReturnRows(rows)
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, time.Now(), time.Now())
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO reviews").WithArgs(review.Author, review.Rating, review.Title, review.Description, review.SubjectType, review.SubjectID, model.StatusPending, []byte("{}"), "english").WillReturnRows(rows)
+				mock.ExpectCommit()
 			},
 			expectedID: 1,
 		},
+		{
+			name: "with subject",
+			inputReview: &model.Review{
+				Author:      "example_mail@example.com",
+				Rating:      3,
+				Title:       "Review Title",
+				Description: "Description of the review",
+				SubjectType: "product",
+				SubjectID:   "sku-42",
+			},
+			mockBehavior: func(review *model.Review) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(2, time.Now(), time.Now())
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO reviews").WithArgs(review.Author, review.Rating, review.Title, review.Description, "product", "sku-42", model.StatusPending, []byte("{}"), "english").WillReturnRows(rows)
+				mock.ExpectCommit()
+			},
+			expectedID: 2,
+		},
 		{
 			name: "invalid email",
 			inputReview: &model.Review{
@@ -59,7 +85,7 @@ func TestReviewRepository_Create(t *testing.T) {
 		t.Run(testcase.name, func(t *testing.T) {
 			testcase.mockBehavior(testcase.inputReview)
 
-			id, err := store.Review().Create(testcase.inputReview)
+			id, err := store.Review().Create(context.Background(), testcase.inputReview)
 
 			if testcase.expectError {
 				assert.Error(t, err)
@@ -71,6 +97,105 @@ func TestReviewRepository_Create(t *testing.T) {
 	}
 }
 
+func TestReviewRepository_CreateSearchLanguage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+	store.SetSearchLanguage("german")
+
+	review := model.TestReview(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO reviews").WithArgs(review.Author, review.Rating, review.Title, review.Description, "", "", model.StatusPending, []byte("{}"), "german").WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, time.Now(), time.Now()))
+	mock.ExpectCommit()
+
+	_, err = store.Review().Create(context.Background(), review)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReviewRepository_CreateIdempotent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	s := postgres.New(db)
+	s.EnableIdempotency(time.Hour)
+
+	ctx := store.WithIdempotencyKey(context.Background(), "message-1")
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	stored := func() *sqlmock.Rows {
+		return sqlmock.NewRows(reviewColumns).AddRow(1, "example_mail@example.com", 7, "Stored title", "Stored description", "", "", model.StatusPublished, 0, 0, []byte("{}"), createdAt, createdAt)
+	}
+
+	testTable := []struct {
+		name         string
+		mockBehavior func()
+		expectedID   int
+		expectReplay bool
+	}{
+		{
+			name: "new key",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("FROM reviews WHERE id = \\(SELECT review_id FROM review_idempotency_keys").WithArgs("example_mail@example.com", "message-1").WillReturnRows(sqlmock.NewRows(reviewColumns))
+				mock.ExpectQuery("INSERT INTO reviews").WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, time.Now(), time.Now()))
+				mock.ExpectExec("INSERT INTO review_idempotency_keys").WithArgs("example_mail@example.com", "message-1", 1, time.Hour.Milliseconds()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedID: 1,
+		},
+		{
+			name: "existing key",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("FROM reviews WHERE id = \\(SELECT review_id FROM review_idempotency_keys").WithArgs("example_mail@example.com", "message-1").WillReturnRows(stored())
+				mock.ExpectRollback()
+			},
+			expectedID:   1,
+			expectReplay: true,
+		},
+		{
+			name: "concurrent insert",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("FROM reviews WHERE id = \\(SELECT review_id FROM review_idempotency_keys").WithArgs("example_mail@example.com", "message-1").WillReturnRows(sqlmock.NewRows(reviewColumns))
+				mock.ExpectQuery("INSERT INTO reviews").WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(2, time.Now(), time.Now()))
+				mock.ExpectExec("INSERT INTO review_idempotency_keys").WithArgs("example_mail@example.com", "message-1", 2, time.Hour.Milliseconds()).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				mock.ExpectQuery("FROM reviews WHERE id = \\(SELECT review_id FROM review_idempotency_keys").WithArgs("example_mail@example.com", "message-1").WillReturnRows(stored())
+			},
+			expectedID:   1,
+			expectReplay: true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockBehavior()
+
+			review := model.TestReview(t)
+			id, err := s.Review().Create(ctx, review)
+
+			assert.NoError(t, err)
+			assert.Equal(t, testcase.expectedID, id)
+			assert.Equal(t, testcase.expectedID, review.ID)
+			if testcase.expectReplay {
+				assert.Equal(t, "Stored title", review.Title)
+				assert.Equal(t, model.StatusPublished, review.Status)
+				assert.Equal(t, createdAt, review.CreatedAt)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestReviewRepository_Update(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -82,6 +207,15 @@ func TestReviewRepository_Update(t *testing.T) {
 
 	type mockBehavior func(review *model.Review)
 
+	updated := func(review *model.Review) *sqlmock.Rows {
+		author := review.Author
+		if author == "" {
+			author = "example_mail@example.com"
+		}
+
+		return sqlmock.NewRows(reviewColumns).AddRow(review.ID, author, review.Rating, review.Title, review.Description, "", "", model.StatusPublished, 0, 0, []byte("{}"), time.Now(), time.Now())
+	}
+
 	testTable := []struct {
 		name         string
 		inputReview  *model.Review
@@ -98,9 +232,10 @@ func TestReviewRepository_Update(t *testing.T) {
 				Description: "review description",
 			},
 			mockBehavior: func(review *model.Review) {
-				mockStmt := mock.ExpectPrepare("UPDATE reviews")
-				mockRes := mockStmt.ExpectExec().WithArgs(review.ID, review.Author, review.Rating, review.Title, review.Description)
-				mockRes.WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO review_revisions").WithArgs(review.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("UPDATE reviews").WithArgs(review.ID, review.Author, review.Rating, review.Title, review.Description, []byte("{}")).WillReturnRows(updated(review))
+				mock.ExpectCommit()
 
 			},
 		},
@@ -114,7 +249,9 @@ func TestReviewRepository_Update(t *testing.T) {
 				Description: "review description",
 			},
 			mockBehavior: func(review *model.Review) {
-				mock.ExpectPrepare("UPDATE reviews").ExpectExec().WithArgs(review.ID, review.Author, review.Rating, review.Title, review.Description).WillReturnResult(sqlmock.NewResult(1, 0))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO review_revisions").WithArgs(review.ID).WillReturnResult(sqlmock.NewResult(1, 0))
+				mock.ExpectRollback()
 			},
 			expectError: true,
 		},
@@ -140,9 +277,10 @@ func TestReviewRepository_Update(t *testing.T) {
 				Description: "review description",
 			},
 			mockBehavior: func(review *model.Review) {
-				mockStmt := mock.ExpectPrepare("UPDATE reviews")
-				mockRes := mockStmt.ExpectExec().WithArgs(review.ID, review.Author, review.Rating, review.Title, review.Description)
-				mockRes.WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO review_revisions").WithArgs(review.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("UPDATE reviews").WithArgs(review.ID, review.Author, review.Rating, review.Title, review.Description, []byte("{}")).WillReturnRows(updated(review))
+				mock.ExpectCommit()
 			},
 		},
 	}
@@ -151,12 +289,14 @@ func TestReviewRepository_Update(t *testing.T) {
 		t.Run(testcase.name, func(t *testing.T) {
 			testcase.mockBehavior(testcase.inputReview)
 
-			err := store.Review().Update(testcase.inputReview)
+			err := store.Review().Update(context.Background(), testcase.inputReview)
 
 			if testcase.expectError {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, model.StatusPublished, testcase.inputReview.Status)
+				assert.NotEmpty(t, testcase.inputReview.Author)
 			}
 		})
 	}
@@ -184,7 +324,9 @@ func TestReviewRepository_Delete(t *testing.T) {
 			name:    "valid",
 			inputId: 1,
 			mockBehavior: func(id int) {
-				mock.ExpectPrepare("DELETE FROM reviews").ExpectExec().WithArgs(id).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE reviews SET deleted_at = now()").WithArgs(id).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
 		},
 		{
@@ -198,7 +340,9 @@ func TestReviewRepository_Delete(t *testing.T) {
 			name:    "invalid id",
 			inputId: 112314,
 			mockBehavior: func(id int) {
-				mock.ExpectPrepare("DELETE FROM reviews").ExpectExec().WithArgs(id).WillReturnResult(sqlmock.NewResult(1, 0))
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE reviews SET deleted_at = now()").WithArgs(id).WillReturnResult(sqlmock.NewResult(1, 0))
+				mock.ExpectRollback()
 			},
 			expectError: true,
 		},
@@ -208,7 +352,7 @@ func TestReviewRepository_Delete(t *testing.T) {
 		t.Run(testcase.name, func(t *testing.T) {
 			testcase.mockBehavior(testcase.inputId)
 
-			err := store.Review().Delete(testcase.inputId)
+			err := store.Review().Delete(context.Background(), testcase.inputId)
 
 			if testcase.expectError {
 				assert.Error(t, err)
@@ -241,8 +385,8 @@ func TestReviewRepository_FindOne(t *testing.T) {
 			name:    "valid",
 			inputId: 1,
 			mockBehavior: func(id int) {
-				rows := mock.NewRows([]string{"id", "author", "rating", "title", "description"}).AddRow(1, "example_mail.@example.com", 3, "review title", "review description")
-				mock.ExpectQuery("SELECT id, author, rating, title, description FROM reviews").WithArgs(id).WillReturnRows(rows)
+				rows := mock.NewRows(reviewColumns).AddRow(1, "example_mail.@example.com", 3, "review title", "review description", "", "", "published", 0, 0, []byte("{}"), time.Time{}, time.Time{})
+				mock.ExpectQuery("SELECT id, author, rating, title, description, subject_type, subject_id, status, helpful_votes, unhelpful_votes, aspects, created_at, updated_at FROM reviews").WithArgs(id).WillReturnRows(rows)
 			},
 			expectedReview: &model.Review{
 				ID:          1,
@@ -250,6 +394,26 @@ func TestReviewRepository_FindOne(t *testing.T) {
 				Rating:      3,
 				Title:       "review title",
 				Description: "review description",
+				Status:      model.StatusPublished,
+			},
+		},
+		{
+			name:    "with aspects",
+			inputId: 2,
+			mockBehavior: func(id int) {
+				rows := mock.NewRows(reviewColumns).AddRow(2, "example_mail.@example.com", 8, "review title", "review description", "hotel", "h-1", "published", 0, 0, []byte(`{"cleanliness": 9, "value": 7}`), time.Time{}, time.Time{})
+				mock.ExpectQuery("SELECT id, author, rating, title, description, subject_type, subject_id, status, helpful_votes, unhelpful_votes, aspects, created_at, updated_at FROM reviews").WithArgs(id).WillReturnRows(rows)
+			},
+			expectedReview: &model.Review{
+				ID:          2,
+				Author:      "example_mail.@example.com",
+				Rating:      8,
+				Aspects:     map[string]int8{"cleanliness": 9, "value": 7},
+				Title:       "review title",
+				Description: "review description",
+				SubjectType: "hotel",
+				SubjectID:   "h-1",
+				Status:      model.StatusPublished,
 			},
 		},
 		{
@@ -274,7 +438,7 @@ func TestReviewRepository_FindOne(t *testing.T) {
 	for _, testcase := range testTable {
 		t.Run(testcase.name, func(t *testing.T) {
 			testcase.mockBehavior(testcase.inputId)
-			returnedReview, err := store.Review().FindOne(testcase.inputId)
+			returnedReview, err := store.Review().FindOne(context.Background(), testcase.inputId)
 
 			if testcase.expectedReview != nil {
 				assert.NoError(t, err)
@@ -306,8 +470,8 @@ func TestReviewRepository_FindAll(t *testing.T) {
 		{
 			name: "1 review",
 			mockBehavior: func() {
-				rows := mock.NewRows([]string{"id", "author", "rating", "title", "description"}).AddRow(1, "example_mail.@example.com", 3, "review title", "review description")
-				mock.ExpectQuery("SELECT id, author, rating, title, description FROM reviews").WithoutArgs().WillReturnRows(rows)
+				rows := mock.NewRows(reviewColumns).AddRow(1, "example_mail.@example.com", 3, "review title", "review description", "", "", "published", 0, 0, []byte("{}"), time.Time{}, time.Time{})
+				mock.ExpectQuery("SELECT id, author, rating, title, description, subject_type, subject_id, status, helpful_votes, unhelpful_votes, aspects, created_at, updated_at FROM reviews").WithoutArgs().WillReturnRows(rows)
 			},
 
 			expectedLen: 1,
@@ -315,8 +479,8 @@ func TestReviewRepository_FindAll(t *testing.T) {
 		{
 			name: "3 review",
 			mockBehavior: func() {
-				rows := mock.NewRows([]string{"id", "author", "rating", "title", "description"}).AddRow(1, "example_mail.@example.com", 3, "review title", "review description").AddRow(1, "example_mail.@example.com", 3, "review title", "review description").AddRow(1, "example_mail.@example.com", 3, "review title", "review description")
-				mock.ExpectQuery("SELECT id, author, rating, title, description FROM reviews").WithoutArgs().WillReturnRows(rows)
+				rows := mock.NewRows(reviewColumns).AddRow(1, "example_mail.@example.com", 3, "review title", "review description", "", "", "published", 0, 0, []byte("{}"), time.Time{}, time.Time{}).AddRow(1, "example_mail.@example.com", 3, "review title", "review description", "", "", "published", 0, 0, []byte("{}"), time.Time{}, time.Time{}).AddRow(1, "example_mail.@example.com", 3, "review title", "review description", "", "", "published", 0, 0, []byte("{}"), time.Time{}, time.Time{})
+				mock.ExpectQuery("SELECT id, author, rating, title, description, subject_type, subject_id, status, helpful_votes, unhelpful_votes, aspects, created_at, updated_at FROM reviews").WithoutArgs().WillReturnRows(rows)
 
 			},
 
@@ -325,8 +489,8 @@ func TestReviewRepository_FindAll(t *testing.T) {
 		{
 			name: "0 review",
 			mockBehavior: func() {
-				rows := mock.NewRows([]string{"id", "author", "rating", "title", "description"})
-				mock.ExpectQuery("SELECT id, author, rating, title, description FROM reviews").WithoutArgs().WillReturnRows(rows)
+				rows := mock.NewRows(reviewColumns)
+				mock.ExpectQuery("SELECT id, author, rating, title, description, subject_type, subject_id, status, helpful_votes, unhelpful_votes, aspects, created_at, updated_at FROM reviews").WithoutArgs().WillReturnRows(rows)
 			},
 			expectedLen: 0,
 		},
@@ -335,9 +499,548 @@ func TestReviewRepository_FindAll(t *testing.T) {
 	for _, testcase := range testTable {
 		t.Run(testcase.name, func(t *testing.T) {
 			testcase.mockBehavior()
-			returnedReviews, err := store.Review().FindAll()
+			returnedReviews, err := store.Review().FindAll(context.Background())
 			assert.NoError(t, err)
 			assert.Len(t, returnedReviews, testcase.expectedLen)
 		})
 	}
 }
+
+func TestReviewRepository_FindPage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	type mockBehavior func()
+
+	testTable := []struct {
+		name           string
+		inputQuery     *model.ReviewQuery
+		mockBehavior   mockBehavior
+		expectedLen    int
+		expectedTotal  int
+		expectedCursor string
+		expectError    bool
+	}{
+		{
+			name:       "first page",
+			inputQuery: &model.ReviewQuery{Limit: 2},
+			mockBehavior: func() {
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM reviews WHERE deleted_at IS NULL`).WithoutArgs().WillReturnRows(mock.NewRows([]string{"count"}).AddRow(3))
+
+				rows := mock.NewRows(reviewColumns).AddRow(1, "example_mail.@example.com", 3, "review title", "review description", "", "", "published", 0, 0, []byte("{}"), time.Time{}, time.Time{}).AddRow(2, "example_mail.@example.com", 4, "review title", "review description", "", "", "published", 0, 0, []byte("{}"), time.Time{}, time.Time{})
+				mock.ExpectQuery(`SELECT id, author, rating, title, description, subject_type, subject_id, status, helpful_votes, unhelpful_votes, aspects, created_at, updated_at FROM reviews WHERE deleted_at IS NULL ORDER BY id ASC, id ASC LIMIT \$1 OFFSET \$2`).WithArgs(2, 0).WillReturnRows(rows)
+			},
+			expectedLen:    2,
+			expectedTotal:  3,
+			expectedCursor: model.EncodeCursor(2),
+		},
+		{
+			name:       "filtered and sorted",
+			inputQuery: &model.ReviewQuery{MinRating: 2, MaxRating: 5, Author: "example_mail@example.com", SortBy: "rating", SortOrder: "desc"},
+			mockBehavior: func() {
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM reviews WHERE deleted_at IS NULL AND rating >= \$1 AND rating <= \$2 AND author = \$3`).WithArgs(2, 5, "example_mail@example.com").WillReturnRows(mock.NewRows([]string{"count"}).AddRow(1))
+
+				rows := mock.NewRows(reviewColumns).AddRow(1, "example_mail@example.com", 3, "review title", "review description", "", "", "published", 0, 0, []byte("{}"), time.Time{}, time.Time{})
+				mock.ExpectQuery(`SELECT id, author, rating, title, description, subject_type, subject_id, status, helpful_votes, unhelpful_votes, aspects, created_at, updated_at FROM reviews WHERE deleted_at IS NULL AND rating >= \$1 AND rating <= \$2 AND author = \$3 ORDER BY rating DESC, id DESC LIMIT \$4 OFFSET \$5`).WithArgs(2, 5, "example_mail@example.com", model.DefaultPageLimit, 0).WillReturnRows(rows)
+			},
+			expectedLen:   1,
+			expectedTotal: 1,
+		},
+		{
+			name:       "sorted by helpfulness",
+			inputQuery: &model.ReviewQuery{SortBy: "helpfulness", SortOrder: "desc"},
+			mockBehavior: func() {
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM reviews WHERE deleted_at IS NULL`).WithoutArgs().WillReturnRows(mock.NewRows([]string{"count"}).AddRow(1))
+
+				rows := mock.NewRows(reviewColumns).AddRow(1, "example_mail@example.com", 3, "review title", "review description", "", "", "published", 4, 1, []byte("{}"), time.Time{}, time.Time{})
+				mock.ExpectQuery(`ORDER BY review_helpfulness\(helpful_votes, unhelpful_votes\) DESC, id DESC LIMIT \$1 OFFSET \$2`).WithArgs(model.DefaultPageLimit, 0).WillReturnRows(rows)
+			},
+			expectedLen:   1,
+			expectedTotal: 1,
+		},
+		{
+			name:         "invalid query",
+			inputQuery:   &model.ReviewQuery{SortOrder: "sideways"},
+			mockBehavior: func() {},
+			expectError:  true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockBehavior()
+			page, err := store.Review().FindPage(context.Background(), testcase.inputQuery)
+
+			if testcase.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, page)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, page.Reviews, testcase.expectedLen)
+				assert.Equal(t, testcase.expectedTotal, page.Total)
+				assert.Equal(t, testcase.expectedCursor, page.NextCursor)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReviewRepository_CountBySubject(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	type mockBehavior func(subject *model.Subject)
+
+	testTable := []struct {
+		name          string
+		inputSubject  *model.Subject
+		mockBehavior  mockBehavior
+		expectedCount int
+		expectError   bool
+	}{
+		{
+			name:         "valid",
+			inputSubject: &model.Subject{Type: "product", ID: "sku-42"},
+			mockBehavior: func(subject *model.Subject) {
+				rows := mock.NewRows([]string{"count"}).AddRow(4)
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM reviews WHERE subject_type=\$1 AND subject_id=\$2 AND status=\$3`).WithArgs(subject.Type, subject.ID, model.StatusPublished).WillReturnRows(rows)
+			},
+			expectedCount: 4,
+		},
+		{
+			name:         "missing type",
+			inputSubject: &model.Subject{ID: "sku-42"},
+			mockBehavior: func(subject *model.Subject) {},
+			expectError:  true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockBehavior(testcase.inputSubject)
+			count, err := store.Review().CountBySubject(context.Background(), testcase.inputSubject)
+
+			if testcase.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testcase.expectedCount, count)
+			}
+		})
+	}
+}
+
+func TestReviewRepository_Stats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	type mockBehavior func(subject *model.Subject)
+
+	testTable := []struct {
+		name          string
+		inputSubject  *model.Subject
+		mockBehavior  mockBehavior
+		expectedStats *model.ReviewStats
+	}{
+		{
+			name:         "valid",
+			inputSubject: &model.Subject{Type: "product", ID: "sku-42"},
+			mockBehavior: func(subject *model.Subject) {
+				rows := mock.NewRows([]string{"rating_count", "rating_sum", "histogram", "prior_mean"}).AddRow(3, 21, "{0,0,0,0,0,1,1,1,0,0}", 6.5)
+				mock.ExpectQuery("FROM review_stats").WithArgs(subject.Type, subject.ID).WillReturnRows(rows)
+
+				aspectRows := mock.NewRows([]string{"key", "count", "sum"}).AddRow("battery_life", 2, 15)
+				mock.ExpectQuery("jsonb_each_text").WithArgs(subject.Type, subject.ID, model.StatusPublished).WillReturnRows(aspectRows)
+			},
+			expectedStats: &model.ReviewStats{
+				SubjectType: "product",
+				SubjectID:   "sku-42",
+				Count:       3,
+				Sum:         21,
+				PriorMean:   6.5,
+				Histogram:   [model.HistogramSize]int{5: 1, 6: 1, 7: 1},
+				Aspects: map[string]*model.AspectStats{
+					"battery_life": {Count: 2, Sum: 15},
+				},
+			},
+		},
+		{
+			name:         "missing id",
+			inputSubject: &model.Subject{Type: "product"},
+			mockBehavior: func(subject *model.Subject) {},
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockBehavior(testcase.inputSubject)
+			stats, err := store.Review().Stats(context.Background(), testcase.inputSubject)
+
+			if testcase.expectedStats != nil {
+				assert.NoError(t, err)
+				assert.EqualValues(t, testcase.expectedStats, stats)
+			} else {
+				assert.Error(t, err)
+				assert.Nil(t, stats)
+			}
+		})
+	}
+}
+
+func TestReviewRepository_FindRevisions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	type mockBehavior func(id int)
+
+	testTable := []struct {
+		name         string
+		inputId      int
+		mockBehavior mockBehavior
+		expectedLen  int
+		expectError  bool
+	}{
+		{
+			name:    "valid",
+			inputId: 1,
+			mockBehavior: func(id int) {
+				mock.ExpectQuery("SELECT EXISTS").WithArgs(id).WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+
+				rows := mock.NewRows([]string{"id", "review_id", "author", "rating", "title", "description", "aspects", "created_at", "revised_at"}).AddRow(1, id, "example_mail@example.com", 3, "review title", "review description", []byte(`{}`), time.Now(), time.Now()).AddRow(2, id, "example_mail@example.com", 4, "review title", "review description", []byte(`{"delivery":5}`), time.Now(), time.Now())
+				mock.ExpectQuery("FROM review_revisions").WithArgs(id).WillReturnRows(rows)
+			},
+			expectedLen: 2,
+		},
+		{
+			name:    "not existing review",
+			inputId: 123,
+			mockBehavior: func(id int) {
+				mock.ExpectQuery("SELECT EXISTS").WithArgs(id).WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expectError: true,
+		},
+		{
+			name:         "empty id",
+			inputId:      0,
+			mockBehavior: func(id int) {},
+			expectError:  true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockBehavior(testcase.inputId)
+			revisions, err := store.Review().FindRevisions(context.Background(), testcase.inputId)
+
+			if testcase.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, revisions)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, revisions, testcase.expectedLen)
+				assert.Nil(t, revisions[0].Aspects)
+				assert.Equal(t, map[string]int8{"delivery": 5}, revisions[1].Aspects)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReviewRepository_Restore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	type mockBehavior func(id int)
+
+	testTable := []struct {
+		name         string
+		inputId      int
+		mockBehavior mockBehavior
+		expectError  bool
+	}{
+		{
+			name:    "valid",
+			inputId: 1,
+			mockBehavior: func(id int) {
+				mock.ExpectExec("UPDATE reviews SET deleted_at = NULL").WithArgs(id).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:         "empty id",
+			inputId:      0,
+			mockBehavior: func(id int) {},
+			expectError:  true,
+		},
+		{
+			name:    "not deleted review",
+			inputId: 2,
+			mockBehavior: func(id int) {
+				mock.ExpectExec("UPDATE reviews SET deleted_at = NULL").WithArgs(id).WillReturnResult(sqlmock.NewResult(1, 0))
+			},
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockBehavior(testcase.inputId)
+
+			err := store.Review().Restore(context.Background(), testcase.inputId)
+
+			if testcase.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReviewRepository_Purge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	deletedBefore := time.Now().Add(-time.Hour)
+	mock.ExpectExec("DELETE FROM reviews WHERE deleted_at < ").WithArgs(deletedBefore).WillReturnResult(sqlmock.NewResult(0, 3))
+
+	count, err := store.Review().Purge(context.Background(), deletedBefore)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReviewRepository_SetStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	type mockBehavior func(moderation *model.Moderation)
+
+	testTable := []struct {
+		name            string
+		inputModeration *model.Moderation
+		mockBehavior    mockBehavior
+		expectError     bool
+	}{
+		{
+			name:            "valid",
+			inputModeration: &model.Moderation{ReviewID: 1, Moderator: "moderator@example.com", Reason: "spam", Status: model.StatusRejected},
+			mockBehavior: func(moderation *model.Moderation) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT status FROM reviews WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE").WithArgs(moderation.ReviewID).WillReturnRows(mock.NewRows([]string{"status"}).AddRow(model.StatusPending))
+				mock.ExpectExec("UPDATE reviews SET status").WithArgs(moderation.ReviewID, moderation.Status).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("INSERT INTO review_moderations").WithArgs(moderation.ReviewID, moderation.Status, moderation.Moderator, moderation.Reason).WillReturnRows(mock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:            "not existing review",
+			inputModeration: &model.Moderation{ReviewID: 2, Moderator: "moderator@example.com", Status: model.StatusPublished},
+			mockBehavior: func(moderation *model.Moderation) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT status FROM reviews").WithArgs(moderation.ReviewID).WillReturnRows(mock.NewRows([]string{"status"}))
+				mock.ExpectRollback()
+			},
+			expectError: true,
+		},
+		{
+			name:            "invalid transition",
+			inputModeration: &model.Moderation{ReviewID: 3, Moderator: "moderator@example.com", Status: model.StatusPublished},
+			mockBehavior: func(moderation *model.Moderation) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT status FROM reviews").WithArgs(moderation.ReviewID).WillReturnRows(mock.NewRows([]string{"status"}).AddRow(model.StatusPublished))
+				mock.ExpectRollback()
+			},
+			expectError: true,
+		},
+		{
+			name:            "empty id",
+			inputModeration: &model.Moderation{Moderator: "moderator@example.com", Status: model.StatusPublished},
+			mockBehavior:    func(moderation *model.Moderation) {},
+			expectError:     true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockBehavior(testcase.inputModeration)
+
+			err := store.Review().SetStatus(context.Background(), testcase.inputModeration)
+
+			if testcase.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.False(t, testcase.inputModeration.CreatedAt.IsZero())
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReviewRepository_Vote(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	type mockBehavior func(vote *model.Vote)
+
+	testTable := []struct {
+		name         string
+		inputVote    *model.Vote
+		mockBehavior mockBehavior
+		expectError  bool
+	}{
+		{
+			name:      "valid",
+			inputVote: model.TestVote(t, 1, true),
+			mockBehavior: func(vote *model.Vote) {
+				mock.ExpectQuery("INSERT INTO review_votes").WithArgs(vote.ReviewID, vote.Voter, true).WillReturnRows(mock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+			},
+		},
+		{
+			name:      "not existing review",
+			inputVote: model.TestVote(t, 2, false),
+			mockBehavior: func(vote *model.Vote) {
+				mock.ExpectQuery("INSERT INTO review_votes").WithArgs(vote.ReviewID, vote.Voter, false).WillReturnError(sql.ErrNoRows)
+			},
+			expectError: true,
+		},
+		{
+			name:         "missing helpful",
+			inputVote:    &model.Vote{ReviewID: 1, Voter: "voter@example.com"},
+			mockBehavior: func(vote *model.Vote) {},
+			expectError:  true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockBehavior(testcase.inputVote)
+
+			err := store.Review().Vote(context.Background(), testcase.inputVote)
+
+			if testcase.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.False(t, testcase.inputVote.CreatedAt.IsZero())
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReviewRepository_Search(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	store := postgres.New(db)
+
+	type mockBehavior func()
+
+	searchColumns := append(append([]string{}, reviewColumns...), "rank", "ts_headline")
+
+	testTable := []struct {
+		name            string
+		inputQuery      *model.SearchQuery
+		mockBehavior    mockBehavior
+		expectedLen     int
+		expectedCursor  string
+		expectedSnippet string
+		expectError     bool
+	}{
+		{
+			name:       "valid",
+			inputQuery: &model.SearchQuery{Query: "battery", Limit: 1, Language: "english", Status: model.StatusPublished},
+			mockBehavior: func() {
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM reviews, websearch_to_tsquery\(\$2::regconfig, \$3\) q WHERE deleted_at IS NULL AND status = \$1 AND search_vector @@ q`).WithArgs(model.StatusPublished, "english", "battery").WillReturnRows(mock.NewRows([]string{"count"}).AddRow(2))
+
+				rows := mock.NewRows(searchColumns).AddRow(1, "example_mail@example.com", 9, "Great battery", "Lasts two days", "", "", "published", 0, 0, []byte("{}"), time.Time{}, time.Time{}, 0.6, "Great \uE000battery\uE001 <b>Lasts</b> two days")
+				mock.ExpectQuery(`ts_rank\(search_vector, q\) AS rank, ts_headline\(\$2::regconfig, title \|\| ' ' \|\| description, q, \$4\) FROM reviews, websearch_to_tsquery\(\$2::regconfig, \$3\) q WHERE deleted_at IS NULL AND status = \$1 AND search_vector @@ q ORDER BY rank DESC, id ASC LIMIT \$5 OFFSET \$6`).WithArgs(model.StatusPublished, "english", "battery", sqlmock.AnyArg(), 1, 0).WillReturnRows(rows)
+			},
+			expectedLen:     1,
+			expectedCursor:  model.EncodeCursor(1),
+			expectedSnippet: "Great <mark>battery</mark> &lt;b&gt;Lasts&lt;/b&gt; two days",
+		},
+		{
+			name:       "german",
+			inputQuery: &model.SearchQuery{Query: "Akkus", Limit: 1, Language: "german"},
+			mockBehavior: func() {
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM reviews, websearch_to_tsquery\(\$1::regconfig, \$2\) q WHERE deleted_at IS NULL AND search_vector @@ q`).WithArgs("german", "Akkus").WillReturnRows(mock.NewRows([]string{"count"}).AddRow(1))
+
+				rows := mock.NewRows(searchColumns).AddRow(1, "example_mail@example.com", 9, "Guter Akku", "Hält zwei Tage", "", "", "published", 0, 0, []byte("{}"), time.Time{}, time.Time{}, 0.6, "Guter \uE000Akku\uE001 Hält zwei Tage")
+				mock.ExpectQuery(`ts_headline\(\$1::regconfig`).WithArgs("german", "Akkus", sqlmock.AnyArg(), 1, 0).WillReturnRows(rows)
+			},
+			expectedLen:     1,
+			expectedSnippet: "Guter <mark>Akku</mark> Hält zwei Tage",
+		},
+		{
+			name:         "empty query",
+			inputQuery:   &model.SearchQuery{Query: " "},
+			mockBehavior: func() {},
+			expectError:  true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockBehavior()
+			page, err := store.Review().Search(context.Background(), testcase.inputQuery)
+
+			if testcase.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, page)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, page.Results, testcase.expectedLen)
+				assert.Equal(t, testcase.expectedCursor, page.NextCursor)
+				assert.Equal(t, 0.6, page.Results[0].Rank)
+				assert.Equal(t, testcase.expectedSnippet, page.Results[0].Snippet)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package postgres_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/Restyx/golang-reviews-service/internal/model"
@@ -17,7 +18,7 @@ func TestPostgresReviewRepository_Create(t *testing.T) {
 	testTable := []struct {
 		name        string
 		inputReview *model.Review
-		create      func(*model.Review) (int, error)
+		create      func(context.Context, *model.Review) (int, error)
 		expectError bool
 	}{
 		{
@@ -46,7 +47,7 @@ func TestPostgresReviewRepository_Create(t *testing.T) {
 
 	for _, testcase := range testTable {
 		t.Run(testcase.name, func(t *testing.T) {
-			id, err := testcase.create(testcase.inputReview)
+			id, err := testcase.create(context.Background(), testcase.inputReview)
 
 			if testcase.expectError {
 				assert.Error(t, err)
@@ -67,7 +68,7 @@ func TestPostgresReviewRepository_FindOne(t *testing.T) {
 
 	baseReview := model.TestReview(t)
 
-	id, err := store.Review().Create(baseReview)
+	id, err := store.Review().Create(context.Background(), baseReview)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -91,7 +92,7 @@ func TestPostgresReviewRepository_FindOne(t *testing.T) {
 
 	for _, testcase := range testTable {
 		t.Run(testcase.name, func(t *testing.T) {
-			returnedReview, err := store.Review().FindOne(testcase.inputId)
+			returnedReview, err := store.Review().FindOne(context.Background(), testcase.inputId)
 
 			if testcase.expectedReview != nil {
 				assert.NoError(t, err)
@@ -120,18 +121,18 @@ func TestPostgresReviewRepository_FindAll(t *testing.T) {
 		{
 			name: "empty table",
 			fillAndFind: func() ([]model.Review, error) {
-				return store.Review().FindAll()
+				return store.Review().FindAll(context.Background())
 			},
 			expectedLen: 0,
 		},
 		{
 			name: "3 rows",
 			fillAndFind: func() ([]model.Review, error) {
-				store.Review().Create(baseReview)
-				store.Review().Create(baseReview)
-				store.Review().Create(baseReview)
+				store.Review().Create(context.Background(), baseReview)
+				store.Review().Create(context.Background(), baseReview)
+				store.Review().Create(context.Background(), baseReview)
 
-				return store.Review().FindAll()
+				return store.Review().FindAll(context.Background())
 			},
 			expectedLen: 3,
 		},
@@ -161,7 +162,7 @@ func TestPostgresReviewRepository_Update(t *testing.T) {
 		Description: "review description",
 	}
 
-	id, err := store.Review().Create(baseReview)
+	id, err := store.Review().Create(context.Background(), baseReview)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -187,6 +188,7 @@ func TestPostgresReviewRepository_Update(t *testing.T) {
 				Rating:      3,
 				Title:       "review title",
 				Description: "review description",
+				Status:      model.StatusPending,
 			},
 		},
 		{
@@ -208,6 +210,7 @@ func TestPostgresReviewRepository_Update(t *testing.T) {
 				Rating:      4,
 				Title:       "review title",
 				Description: "review description",
+				Status:      model.StatusPending,
 			},
 		},
 		{
@@ -221,6 +224,7 @@ func TestPostgresReviewRepository_Update(t *testing.T) {
 				Rating:      4,
 				Title:       "review title",
 				Description: "review description",
+				Status:      model.StatusPending,
 			},
 		},
 		{
@@ -238,17 +242,21 @@ func TestPostgresReviewRepository_Update(t *testing.T) {
 
 	for _, testcase := range testcases {
 		t.Run(testcase.name, func(t *testing.T) {
-			err := store.Review().Update(testcase.inputReview)
+			err := store.Review().Update(context.Background(), testcase.inputReview)
 
 			if testcase.expectError {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
 
-				actualReview, err := store.Review().FindOne(id)
+				actualReview, err := store.Review().FindOne(context.Background(), id)
 				if err != nil {
 					t.Fatal(err)
 				}
+				assert.False(t, actualReview.UpdatedAt.Before(actualReview.CreatedAt))
+
+				testcase.expectedReview.CreatedAt = actualReview.CreatedAt
+				testcase.expectedReview.UpdatedAt = actualReview.UpdatedAt
 				assert.EqualValues(t, testcase.expectedReview, actualReview)
 			}
 
@@ -263,7 +271,7 @@ func TestPostgresReviewRepository_Delete(t *testing.T) {
 	store := postgres.New(database)
 
 	baseReview := model.TestReview(t)
-	id, err := store.Review().Create(baseReview)
+	id, err := store.Review().Create(context.Background(), baseReview)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -286,16 +294,40 @@ func TestPostgresReviewRepository_Delete(t *testing.T) {
 
 	for _, testcase := range testcases {
 		t.Run(testcase.name, func(t *testing.T) {
-			err := store.Review().Delete(testcase.inputId)
+			err := store.Review().Delete(context.Background(), testcase.inputId)
 
 			if testcase.expectError {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
 
-				_, err = store.Review().FindOne(testcase.inputId)
+				_, err = store.Review().FindOne(context.Background(), testcase.inputId)
 				assert.Error(t, err)
 			}
 		})
 	}
 }
+
+func TestPostgresReviewRepository_FindRevisions(t *testing.T) {
+	database, teardown := postgres.TestPostgresDB(t, pgUser, pgPass, pgHost, pgPort, pgDB, pgSSL)
+	defer teardown("reviews", "review_revisions")
+
+	store := postgres.New(database)
+
+	id, err := store.Review().Create(context.Background(), model.TestReview(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Review().Update(context.Background(), &model.Review{ID: id, Title: "first edit"}); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := store.Review().FindRevisions(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 1)
+	assert.Equal(t, "Title", revisions[0].Title)
+
+	_, err = store.Review().FindRevisions(context.Background(), id+1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+)
+
+// ts_headline marks matches with private-use runes, so the review text can be
+// HTML-escaped before the marks are turned into tags.
+const (
+	headlineStart = "\uE000"
+	headlineStop  = "\uE001"
+)
+
+var (
+	headlineOptions = fmt.Sprintf("StartSel=%s, StopSel=%s, MaxFragments=2, MaxWords=20, MinWords=5", headlineStart, headlineStop)
+	headlineMarks   = strings.NewReplacer(headlineStart, model.HighlightStart, headlineStop, model.HighlightStop)
+)
+
+func snippet(headline string) string {
+	return headlineMarks.Replace(html.EscapeString(headline))
+}
+
+type extraScanner struct {
+	row   rowScanner
+	extra []interface{}
+}
+
+func (s extraScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
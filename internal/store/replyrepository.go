@@ -0,0 +1,15 @@
+package store
+
+import (
+	"context"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+)
+
+type ReplyRepositoryI interface {
+	Create(context.Context, *model.Reply) (int, error)
+	Update(context.Context, *model.Reply) error
+	Delete(context.Context, int) error
+	FindByReview(context.Context, int) ([]model.Reply, error)
+	FindLatest(context.Context, int, string) (*model.Reply, error)
+}
@@ -0,0 +1,12 @@
+package store
+
+import (
+	"context"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+)
+
+type ReportRepositoryI interface {
+	Create(context.Context, *model.Report) (int, error)
+	CountReporters(context.Context, int) (int, error)
+}
@@ -1,11 +1,25 @@
 package store
 
-import "github.com/Restyx/golang-reviews-service/internal/model"
+import (
+	"context"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+)
 
 type ReviewRepositoryI interface {
-	Create(*model.Review) (int, error)
-	FindOne(int) (*model.Review, error)
-	FindAll() ([]model.Review, error)
-	Update(*model.Review) error
-	Delete(int) error
+	Create(context.Context, *model.Review) (int, error)
+	FindOne(context.Context, int) (*model.Review, error)
+	FindAll(context.Context) ([]model.Review, error)
+	FindPage(context.Context, *model.ReviewQuery) (*model.ReviewPage, error)
+	Update(context.Context, *model.Review) error
+	Delete(context.Context, int) error
+	Restore(context.Context, int) error
+	Purge(context.Context, time.Time) (int, error)
+	SetStatus(context.Context, *model.Moderation) error
+	Vote(context.Context, *model.Vote) error
+	Search(context.Context, *model.SearchQuery) (*model.SearchPage, error)
+	CountBySubject(context.Context, *model.Subject) (int, error)
+	Stats(context.Context, *model.Subject) (*model.ReviewStats, error)
+	FindRevisions(context.Context, int) ([]model.ReviewRevision, error)
 }
@@ -2,4 +2,7 @@ package store
 
 type StoreI interface {
 	Review() ReviewRepositoryI
+	Report() ReportRepositoryI
+	Reply() ReplyRepositoryI
+	Outbox() OutboxRepositoryI
 }
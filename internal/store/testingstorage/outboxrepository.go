@@ -0,0 +1,74 @@
+package testingstorage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store"
+)
+
+type OutboxRepository struct {
+	store    *Store
+	mu       sync.Mutex
+	messages []*model.OutboxMessage
+	lastID   int
+}
+
+func (r *OutboxRepository) record(eventType string, before, after *model.Review) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	r.messages = append(r.messages, &model.OutboxMessage{
+		ID:        r.lastID,
+		Event:     *model.NewEvent(eventType, before, after),
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+func (r *OutboxRepository) Process(ctx context.Context, limit int, publish func(*model.OutboxMessage) error) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pending := make([]model.OutboxMessage, 0, limit)
+	for _, message := range r.messages {
+		if len(pending) == limit {
+			break
+		}
+		if message.SentAt == nil {
+			pending = append(pending, *message)
+		}
+	}
+
+	sent, err := store.PublishInOrder(pending, publish)
+
+	now := time.Now().UTC()
+	for _, id := range sent {
+		for _, message := range r.messages {
+			if message.ID == id {
+				message.SentAt = &now
+			}
+		}
+	}
+
+	return len(sent), err
+}
+
+func (r *OutboxRepository) Cleanup(ctx context.Context, sentBefore time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.messages[:0]
+	for _, message := range r.messages {
+		if message.SentAt == nil || !message.SentAt.Before(sentBefore) {
+			kept = append(kept, message)
+		}
+	}
+
+	count := len(r.messages) - len(kept)
+	r.messages = kept
+
+	return count, nil
+}
@@ -0,0 +1,136 @@
+package testingstorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store"
+)
+
+type ReplyRepository struct {
+	store   *Store
+	replies map[int]*model.Reply
+	lastID  int
+}
+
+func (r *ReplyRepository) deleteByReview(reviewID int) {
+	for id, reply := range r.replies {
+		if reply.ReviewID == reviewID {
+			delete(r.replies, id)
+		}
+	}
+}
+
+func (r *ReplyRepository) Create(ctx context.Context, reply *model.Reply) (int, error) {
+	reply.ID = 0
+	if err := reply.Validate(); err != nil {
+		return 0, err
+	}
+
+	if _, err := r.store.Review().FindOne(ctx, reply.ReviewID); err != nil {
+		return 0, store.NewRecordNotFound(fmt.Sprint(reply.ReviewID))
+	}
+
+	if reply.ParentID != 0 {
+		if parent, ok := r.replies[reply.ParentID]; !ok || parent.ReviewID != reply.ReviewID {
+			return 0, store.NewRecordNotFound(fmt.Sprint(reply.ReviewID))
+		}
+	}
+
+	r.lastID++
+	reply.ID = r.lastID
+	reply.CreatedAt = time.Now().UTC()
+	reply.UpdatedAt = reply.CreatedAt
+
+	stored := *reply
+	r.replies[reply.ID] = &stored
+
+	return reply.ID, nil
+}
+
+func (r *ReplyRepository) Update(ctx context.Context, reply *model.Reply) error {
+	if reply.ID == 0 {
+		return store.NewFieldMissing("id")
+	}
+
+	if err := reply.Validate(); err != nil {
+		return err
+	}
+
+	stored, ok := r.replies[reply.ID]
+	if !ok {
+		return store.NewRecordNotFound(fmt.Sprint(reply.ID))
+	}
+
+	stored.Body = reply.Body
+	stored.UpdatedAt = time.Now().UTC()
+	*reply = *stored
+
+	return nil
+}
+
+func (r *ReplyRepository) Delete(ctx context.Context, id int) error {
+	if id == 0 {
+		return store.NewFieldMissing("id")
+	}
+
+	if _, ok := r.replies[id]; !ok {
+		return store.NewRecordNotFound(fmt.Sprint(id))
+	}
+
+	delete(r.replies, id)
+
+	for childID, reply := range r.replies {
+		if reply.ParentID == id {
+			r.Delete(ctx, childID)
+		}
+	}
+
+	return nil
+}
+
+func (r *ReplyRepository) FindByReview(ctx context.Context, reviewID int) ([]model.Reply, error) {
+	if reviewID == 0 {
+		return nil, store.NewFieldMissing("id")
+	}
+
+	if _, err := r.store.Review().FindOne(ctx, reviewID); err != nil {
+		return nil, err
+	}
+
+	replies := make([]model.Reply, 0)
+	for _, reply := range r.replies {
+		if reply.ReviewID == reviewID {
+			replies = append(replies, *reply)
+		}
+	}
+
+	sort.Slice(replies, func(i, j int) bool {
+		return replies[i].ID < replies[j].ID
+	})
+
+	return replies, nil
+}
+
+func (r *ReplyRepository) FindLatest(ctx context.Context, reviewID int, role string) (*model.Reply, error) {
+	if reviewID == 0 {
+		return nil, store.NewFieldMissing("id")
+	}
+
+	var latest *model.Reply
+	for _, reply := range r.replies {
+		if reply.ReviewID == reviewID && reply.Role == role && (latest == nil || reply.ID > latest.ID) {
+			latest = reply
+		}
+	}
+
+	if latest == nil {
+		return nil, nil
+	}
+
+	result := *latest
+	return &result, nil
+}
@@ -0,0 +1,158 @@
+package testingstorage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store/testingstorage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplyRepository_Create(t *testing.T) {
+	store := testingstorage.New()
+
+	reviewID, err := store.Review().Create(context.Background(), model.TestReview(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parentID, err := store.Reply().Create(context.Background(), model.TestReply(t, reviewID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testTable := []struct {
+		name        string
+		inputReply  func() *model.Reply
+		expectError bool
+	}{
+		{
+			name: "valid",
+			inputReply: func() *model.Reply {
+				return model.TestReply(t, reviewID)
+			},
+		},
+		{
+			name: "nested reply",
+			inputReply: func() *model.Reply {
+				reply := model.TestReply(t, reviewID)
+				reply.ParentID = parentID
+				reply.Role = model.RoleCustomer
+				return reply
+			},
+		},
+		{
+			name: "not existing parent",
+			inputReply: func() *model.Reply {
+				reply := model.TestReply(t, reviewID)
+				reply.ParentID = parentID + 100
+				return reply
+			},
+			expectError: true,
+		},
+		{
+			name: "not existing review",
+			inputReply: func() *model.Reply {
+				return model.TestReply(t, reviewID+1)
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid role",
+			inputReply: func() *model.Reply {
+				reply := model.TestReply(t, reviewID)
+				reply.Role = "admin"
+				return reply
+			},
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			reply := testcase.inputReply()
+			id, err := store.Reply().Create(context.Background(), reply)
+
+			if testcase.expectError {
+				assert.Error(t, err)
+				assert.Zero(t, id)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, reply.ID, id)
+				assert.False(t, reply.CreatedAt.IsZero())
+			}
+		})
+	}
+}
+
+func TestReplyRepository_UpdateDelete(t *testing.T) {
+	store := testingstorage.New()
+
+	reviewID, err := store.Review().Create(context.Background(), model.TestReview(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent := model.TestReply(t, reviewID)
+	if _, err := store.Reply().Create(context.Background(), parent); err != nil {
+		t.Fatal(err)
+	}
+
+	child := model.TestReply(t, reviewID)
+	child.ParentID = parent.ID
+	if _, err := store.Reply().Create(context.Background(), child); err != nil {
+		t.Fatal(err)
+	}
+
+	updated := &model.Reply{ID: parent.ID, Body: " Updated answer "}
+	assert.NoError(t, store.Reply().Update(context.Background(), updated))
+	assert.Equal(t, "Updated answer", updated.Body)
+	assert.Equal(t, parent.Author, updated.Author)
+	assert.Error(t, store.Reply().Update(context.Background(), &model.Reply{ID: child.ID + 1, Body: "Missing"}))
+	assert.Error(t, store.Reply().Update(context.Background(), &model.Reply{Body: "Missing id"}))
+
+	replies, err := store.Reply().FindByReview(context.Background(), reviewID)
+	assert.NoError(t, err)
+	assert.Len(t, replies, 2)
+	assert.Equal(t, "Updated answer", replies[0].Body)
+
+	assert.NoError(t, store.Reply().Delete(context.Background(), parent.ID))
+	assert.Error(t, store.Reply().Delete(context.Background(), parent.ID))
+
+	replies, err = store.Reply().FindByReview(context.Background(), reviewID)
+	assert.NoError(t, err)
+	assert.Empty(t, replies)
+
+	_, err = store.Reply().FindByReview(context.Background(), reviewID+1)
+	assert.Error(t, err)
+}
+
+func TestReplyRepository_FindLatest(t *testing.T) {
+	store := testingstorage.New()
+
+	reviewID, err := store.Review().Create(context.Background(), model.TestReview(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := store.Reply().FindLatest(context.Background(), reviewID, model.RoleMerchant)
+	assert.NoError(t, err)
+	assert.Nil(t, latest)
+
+	first := model.TestReply(t, reviewID)
+	second := model.TestReply(t, reviewID)
+	second.Body = "Follow-up from the shop"
+	customer := model.TestReply(t, reviewID)
+	customer.Role = model.RoleCustomer
+
+	for _, reply := range []*model.Reply{first, second, customer} {
+		if _, err := store.Reply().Create(context.Background(), reply); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	latest, err = store.Reply().FindLatest(context.Background(), reviewID, model.RoleMerchant)
+	assert.NoError(t, err)
+	assert.Equal(t, second, latest)
+}
@@ -0,0 +1,66 @@
+package testingstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store"
+)
+
+type ReportRepository struct {
+	store   *Store
+	reports map[int][]*model.Report
+	lastID  int
+}
+
+func (r *ReportRepository) Create(ctx context.Context, report *model.Report) (int, error) {
+	if err := report.Validate(); err != nil {
+		return 0, err
+	}
+
+	if _, err := r.store.Review().FindOne(ctx, report.ReviewID); err != nil {
+		return 0, store.NewRecordNotFound(fmt.Sprint(report.ReviewID))
+	}
+
+	report.CreatedAt = time.Now().UTC()
+
+	for _, existing := range r.reports[report.ReviewID] {
+		if existing.Reporter == report.Reporter {
+			report.ID = existing.ID
+			*existing = *report
+			return report.ID, nil
+		}
+	}
+
+	r.lastID++
+	report.ID = r.lastID
+
+	stored := *report
+	r.reports[report.ReviewID] = append(r.reports[report.ReviewID], &stored)
+
+	return report.ID, nil
+}
+
+func (r *ReportRepository) CountReporters(ctx context.Context, reviewID int) (int, error) {
+	if reviewID == 0 {
+		return 0, store.NewFieldMissing("review_id")
+	}
+
+	var moderatedAt time.Time
+	if r.store.reviewRepository != nil {
+		if moderations := r.store.reviewRepository.moderations[reviewID]; len(moderations) > 0 {
+			moderatedAt = moderations[len(moderations)-1].CreatedAt
+		}
+	}
+
+	count := 0
+	for _, report := range r.reports[reviewID] {
+		if report.CreatedAt.After(moderatedAt) {
+			count++
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,78 @@
+package testingstorage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store/testingstorage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportRepository_Create(t *testing.T) {
+	store := testingstorage.New()
+
+	id, err := store.Review().Create(context.Background(), model.TestReview(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testTable := []struct {
+		name              string
+		inputReport       *model.Report
+		expectedReporters int
+		expectError       bool
+	}{
+		{
+			name:              "valid",
+			inputReport:       model.TestReport(t, id),
+			expectedReporters: 1,
+		},
+		{
+			name:              "same reporter again",
+			inputReport:       model.TestReport(t, id),
+			expectedReporters: 1,
+		},
+		{
+			name: "another reporter",
+			inputReport: &model.Report{
+				ReviewID: id,
+				Reporter: "another@example.com",
+				Reason:   "abuse",
+			},
+			expectedReporters: 2,
+		},
+		{
+			name:        "not existing review",
+			inputReport: model.TestReport(t, id+1),
+			expectError: true,
+		},
+		{
+			name: "invalid reason",
+			inputReport: &model.Report{
+				ReviewID: id,
+				Reporter: "reporter@example.com",
+				Reason:   "boring",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			reportID, err := store.Report().Create(context.Background(), testcase.inputReport)
+
+			if testcase.expectError {
+				assert.Error(t, err)
+				assert.Zero(t, reportID)
+			} else {
+				assert.NoError(t, err)
+				assert.NotZero(t, reportID)
+
+				reporters, err := store.Report().CountReporters(context.Background(), id)
+				assert.NoError(t, err)
+				assert.Equal(t, testcase.expectedReporters, reporters)
+			}
+		})
+	}
+}
@@ -1,65 +1,261 @@
 package testingstorage
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/Restyx/golang-reviews-service/internal/model"
 	"github.com/Restyx/golang-reviews-service/internal/store"
 )
 
 type ReviewRepository struct {
-	store   *Store
-	reviews map[int]*model.Review
+	store       *Store
+	reviews     map[int]*model.Review
+	revisions   map[int][]model.ReviewRevision
+	deleted     map[int]time.Time
+	moderations map[int][]model.Moderation
+	votes       map[int]map[string]bool
+	keys        map[idempotencyScope]idempotencyKey
+	lastID      int
 }
 
-func (r *ReviewRepository) Create(review *model.Review) (int, error) {
+type idempotencyScope struct {
+	author string
+	key    string
+}
+
+type idempotencyKey struct {
+	reviewID  int
+	expiresAt time.Time
+}
+
+func (r *ReviewRepository) active() []*model.Review {
+	result := make([]*model.Review, 0, len(r.reviews))
+
+	for id, review := range r.reviews {
+		if _, ok := r.deleted[id]; !ok {
+			result = append(result, review)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+
+	return result
+}
+
+func (r *ReviewRepository) find(id int) (*model.Review, bool) {
+	if _, ok := r.deleted[id]; ok {
+		return nil, false
+	}
+
+	review, ok := r.reviews[id]
+	return review, ok
+}
+
+func (r *ReviewRepository) Create(ctx context.Context, review *model.Review) (int, error) {
 	if err := review.Validate(); err != nil {
 		return 0, err
 	}
 
-	review.ID = int(len(r.reviews) + 1)
+	key := store.IdempotencyKey(ctx)
+	if r.store.idempotency <= 0 {
+		key = ""
+	}
+
+	scope := idempotencyScope{author: review.Author, key: key}
+	if stored, ok := r.keys[scope]; ok && key != "" && time.Now().Before(stored.expiresAt) {
+		*review = *r.reviews[stored.reviewID].Clone()
+		return review.ID, nil
+	}
+
+	r.lastID++
+	review.ID = r.lastID
+	if review.Status == "" {
+		review.Status = model.StatusPending
+	}
+	review.CreatedAt = time.Now().UTC()
+	review.UpdatedAt = review.CreatedAt
 
 	r.reviews[review.ID] = review
+	r.store.recordEvent(model.EventReviewCreated, nil, review.Clone())
+
+	if key != "" {
+		r.keys[scope] = idempotencyKey{reviewID: review.ID, expiresAt: time.Now().Add(r.store.idempotency)}
+	}
 
 	return review.ID, nil
 }
 
-func (r *ReviewRepository) FindAll() ([]model.Review, error) {
-	result := make([]model.Review, len(r.reviews))
+func (r *ReviewRepository) FindAll(ctx context.Context) ([]model.Review, error) {
+	result := make([]model.Review, 0, len(r.reviews))
 
-	for index, value := range r.reviews {
-		result[index-1] = *value
+	for _, value := range r.active() {
+		result = append(result, *value)
 	}
 
 	return result, nil
 }
 
-func (r *ReviewRepository) FindOne(id int) (*model.Review, error) {
+func (r *ReviewRepository) FindPage(ctx context.Context, query *model.ReviewQuery) (*model.ReviewPage, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	matched := make([]model.Review, 0, len(r.reviews))
+	for _, review := range r.active() {
+		if query.MinRating != 0 && review.Rating < query.MinRating {
+			continue
+		}
+		if query.MaxRating != 0 && review.Rating > query.MaxRating {
+			continue
+		}
+		if query.Author != "" && review.Author != query.Author {
+			continue
+		}
+		if query.SubjectType != "" && (review.SubjectType != query.SubjectType || review.SubjectID != query.SubjectID) {
+			continue
+		}
+		if query.Status != "" && review.Status != query.Status {
+			continue
+		}
+
+		matched = append(matched, *review)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if query.SortOrder == "desc" {
+			a, b = b, a
+		}
+
+		switch query.SortBy {
+		case "rating":
+			if a.Rating != b.Rating {
+				return a.Rating < b.Rating
+			}
+		case "title":
+			if a.Title != b.Title {
+				return a.Title < b.Title
+			}
+		case "author":
+			if a.Author != b.Author {
+				return a.Author < b.Author
+			}
+		case "helpfulness":
+			if scoreA, scoreB := a.Helpfulness(), b.Helpfulness(); scoreA != scoreB {
+				return scoreA < scoreB
+			}
+		}
+
+		return a.ID < b.ID
+	})
+
+	page := &model.ReviewPage{
+		Reviews: make([]model.Review, 0),
+		Total:   len(matched),
+	}
+
+	if query.Offset < len(matched) {
+		end := min(query.Offset+query.Limit, len(matched))
+		page.Reviews = append(page.Reviews, matched[query.Offset:end]...)
+	}
+
+	page.NextCursor = query.NextCursor(len(page.Reviews), page.Total)
+
+	return page, nil
+}
+
+func (r *ReviewRepository) Search(ctx context.Context, query *model.SearchQuery) (*model.SearchPage, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	terms := tokenize(query.Query)
+
+	matched := make([]model.SearchResult, 0)
+	for _, review := range r.active() {
+		if query.SubjectType != "" && (review.SubjectType != query.SubjectType || review.SubjectID != query.SubjectID) {
+			continue
+		}
+		if query.Status != "" && review.Status != query.Status {
+			continue
+		}
+
+		rank := searchRank(review, terms)
+		if rank == 0 {
+			continue
+		}
+
+		matched = append(matched, model.SearchResult{
+			Review:  *review,
+			Rank:    rank,
+			Snippet: highlight(review.Title+" "+review.Description, terms),
+		})
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Rank > matched[j].Rank
+	})
+
+	page := &model.SearchPage{
+		Results: make([]model.SearchResult, 0),
+		Total:   len(matched),
+	}
+
+	if query.Offset < len(matched) {
+		end := min(query.Offset+query.Limit, len(matched))
+		page.Results = append(page.Results, matched[query.Offset:end]...)
+	}
+
+	page.NextCursor = query.NextCursor(len(page.Results), page.Total)
+
+	return page, nil
+}
+
+func (r *ReviewRepository) FindOne(ctx context.Context, id int) (*model.Review, error) {
 	if id == 0 {
-		return nil, store.ErrFieldMissing.AddFields("id")
+		return nil, store.NewFieldMissing("id")
 	}
 
-	review, ok := r.reviews[id]
+	review, ok := r.find(id)
 	if !ok {
-		return nil, store.ErrRecordNotFound.Record(fmt.Sprint(id))
+		return nil, store.NewRecordNotFound(fmt.Sprint(id))
 	}
 
 	return review, nil
 }
 
-func (r *ReviewRepository) Update(updatedReview *model.Review) error {
+func (r *ReviewRepository) Update(ctx context.Context, updatedReview *model.Review) error {
 	if updatedReview.ID == 0 {
-		return store.ErrFieldMissing.AddFields("id")
+		return store.NewFieldMissing("id")
 	}
 
 	if err := updatedReview.Validate(); err != nil {
 		return err
 	}
 
-	review, ok := r.reviews[int(updatedReview.ID)]
+	review, ok := r.find(updatedReview.ID)
 	if !ok {
-		return store.ErrRecordNotFound
+		return store.NewRecordNotFound(fmt.Sprint(updatedReview.ID))
 	}
+	before := review.Clone()
+
+	now := time.Now().UTC()
+	r.revisions[review.ID] = append(r.revisions[review.ID], model.ReviewRevision{
+		ID:          len(r.revisions[review.ID]) + 1,
+		ReviewID:    review.ID,
+		Author:      review.Author,
+		Rating:      review.Rating,
+		Title:       review.Title,
+		Description: review.Description,
+		Aspects:     before.Aspects,
+		CreatedAt:   review.UpdatedAt,
+		RevisedAt:   now,
+	})
 
 	if updatedReview.Author != "" {
 		review.Author = updatedReview.Author
@@ -82,20 +278,199 @@ func (r *ReviewRepository) Update(updatedReview *model.Review) error {
 		updatedReview.Description = review.Description
 	}
 
+	if len(updatedReview.Aspects) > 0 {
+		if review.Aspects == nil {
+			review.Aspects = make(map[string]int8, len(updatedReview.Aspects))
+		}
+		for name, rating := range updatedReview.Aspects {
+			review.Aspects[name] = rating
+		}
+	}
+
+	review.UpdatedAt = now
+	updatedReview.Aspects = review.Aspects
+	updatedReview.Status = review.Status
+	updatedReview.HelpfulVotes = review.HelpfulVotes
+	updatedReview.UnhelpfulVotes = review.UnhelpfulVotes
+	updatedReview.CreatedAt = review.CreatedAt
+	updatedReview.UpdatedAt = review.UpdatedAt
+
+	r.store.recordEvent(model.EventReviewUpdated, before, review.Clone())
+
+	return nil
+}
+
+func (r *ReviewRepository) Delete(ctx context.Context, id int) error {
+	if id == 0 {
+		return store.NewFieldMissing("id")
+	}
+
+	review, ok := r.find(id)
+	if !ok {
+		return store.NewRecordNotFound(fmt.Sprint(id))
+	}
+
+	r.deleted[id] = time.Now().UTC()
+	r.store.recordEvent(model.EventReviewDeleted, review.Clone(), nil)
+
 	return nil
 }
 
-func (r *ReviewRepository) Delete(id int) error {
+func (r *ReviewRepository) Restore(ctx context.Context, id int) error {
 	if id == 0 {
-		return store.ErrFieldMissing.AddFields("id")
+		return store.NewFieldMissing("id")
+	}
+
+	if _, ok := r.deleted[id]; !ok {
+		return store.NewRecordNotFound(fmt.Sprint(id))
+	}
+
+	delete(r.deleted, id)
+
+	return nil
+}
+
+func (r *ReviewRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	count := 0
+
+	for id, deletedAt := range r.deleted {
+		if deletedAt.Before(deletedBefore) {
+			delete(r.reviews, id)
+			delete(r.revisions, id)
+			delete(r.moderations, id)
+			delete(r.votes, id)
+			if r.store.reportRepository != nil {
+				delete(r.store.reportRepository.reports, id)
+			}
+			if r.store.replyRepository != nil {
+				r.store.replyRepository.deleteByReview(id)
+			}
+			delete(r.deleted, id)
+			count++
+		}
+	}
+
+	now := time.Now()
+	for scope, stored := range r.keys {
+		if _, ok := r.reviews[stored.reviewID]; !ok || !now.Before(stored.expiresAt) {
+			delete(r.keys, scope)
+		}
+	}
+
+	return count, nil
+}
+
+func (r *ReviewRepository) SetStatus(ctx context.Context, moderation *model.Moderation) error {
+	if moderation.ReviewID == 0 {
+		return store.NewFieldMissing("id")
+	}
+
+	review, ok := r.find(moderation.ReviewID)
+	if !ok {
+		return store.NewRecordNotFound(fmt.Sprint(moderation.ReviewID))
+	}
+
+	if !model.CanTransition(review.Status, moderation.Status) {
+		return model.NewInvalidTransition(review.Status, moderation.Status)
 	}
 
-	_, ok := r.reviews[id]
+	review.Status = moderation.Status
+	moderation.CreatedAt = time.Now().UTC()
+	r.moderations[review.ID] = append(r.moderations[review.ID], *moderation)
+
+	return nil
+}
+
+func (r *ReviewRepository) Vote(ctx context.Context, vote *model.Vote) error {
+	if err := vote.Validate(); err != nil {
+		return err
+	}
+
+	review, ok := r.find(vote.ReviewID)
 	if !ok {
-		return store.ErrRecordNotFound.Record(fmt.Sprint(id))
+		return store.NewRecordNotFound(fmt.Sprint(vote.ReviewID))
+	}
+
+	if r.votes[review.ID] == nil {
+		r.votes[review.ID] = make(map[string]bool)
+	}
+
+	if helpful, ok := r.votes[review.ID][vote.Voter]; ok {
+		if helpful {
+			review.HelpfulVotes--
+		} else {
+			review.UnhelpfulVotes--
+		}
+	}
+
+	if *vote.Helpful {
+		review.HelpfulVotes++
+	} else {
+		review.UnhelpfulVotes++
 	}
 
-	delete(r.reviews, id)
+	r.votes[review.ID][vote.Voter] = *vote.Helpful
+	vote.CreatedAt = time.Now().UTC()
 
 	return nil
 }
+
+func (r *ReviewRepository) CountBySubject(ctx context.Context, subject *model.Subject) (int, error) {
+	if err := subject.Validate(); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, review := range r.active() {
+		if review.SubjectType == subject.Type && review.SubjectID == subject.ID && review.Status == model.StatusPublished {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *ReviewRepository) Stats(ctx context.Context, subject *model.Subject) (*model.ReviewStats, error) {
+	if err := subject.Validate(); err != nil {
+		return nil, err
+	}
+
+	stats := &model.ReviewStats{
+		SubjectType: subject.Type,
+		SubjectID:   subject.ID,
+	}
+	global := &model.ReviewStats{}
+
+	for _, review := range r.active() {
+		if review.Status != model.StatusPublished {
+			continue
+		}
+
+		global.Add(review.Rating)
+
+		if review.SubjectType == subject.Type && review.SubjectID == subject.ID {
+			stats.Add(review.Rating)
+			stats.AddAspects(review.Aspects)
+		}
+	}
+
+	global.Calculate(0)
+	stats.PriorMean = global.Mean
+
+	return stats, nil
+}
+
+func (r *ReviewRepository) FindRevisions(ctx context.Context, id int) ([]model.ReviewRevision, error) {
+	if id == 0 {
+		return nil, store.NewFieldMissing("id")
+	}
+
+	if _, ok := r.find(id); !ok {
+		return nil, store.NewRecordNotFound(fmt.Sprint(id))
+	}
+
+	revisions := make([]model.ReviewRevision, len(r.revisions[id]))
+	copy(revisions, r.revisions[id])
+
+	return revisions, nil
+}
@@ -1,9 +1,13 @@
 package testingstorage_test
 
 import (
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store"
 	"github.com/Restyx/golang-reviews-service/internal/store/testingstorage"
 	"github.com/stretchr/testify/assert"
 )
@@ -14,7 +18,7 @@ func TestReviewRepository_Create(t *testing.T) {
 	testTable := []struct {
 		name        string
 		inputReview *model.Review
-		create      func(*model.Review) (int, error)
+		create      func(context.Context, *model.Review) (int, error)
 		expectError bool
 	}{
 		{
@@ -43,7 +47,7 @@ func TestReviewRepository_Create(t *testing.T) {
 
 	for _, testcase := range testTable {
 		t.Run(testcase.name, func(t *testing.T) {
-			id, err := testcase.create(testcase.inputReview)
+			id, err := testcase.create(context.Background(), testcase.inputReview)
 
 			if testcase.expectError {
 				assert.Error(t, err)
@@ -56,12 +60,51 @@ func TestReviewRepository_Create(t *testing.T) {
 	}
 }
 
+func TestReviewRepository_Idempotency(t *testing.T) {
+	storage := testingstorage.New()
+	storage.EnableIdempotency(50 * time.Millisecond)
+
+	ctx := store.WithIdempotencyKey(context.Background(), "message-1")
+
+	first, err := storage.Review().Create(ctx, model.TestReview(t))
+	assert.NoError(t, err)
+
+	resent := model.TestReview(t)
+	resent.Title = "Different title"
+	second, err := storage.Review().Create(ctx, resent)
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, model.TestReview(t).Title, resent.Title)
+	assert.False(t, resent.CreatedAt.IsZero())
+
+	other, err := storage.Review().Create(context.Background(), model.TestReview(t))
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, other)
+
+	otherAuthor := model.TestReview(t)
+	otherAuthor.Author = "other_mail@example.com"
+	scoped, err := storage.Review().Create(ctx, otherAuthor)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, scoped)
+	assert.Equal(t, "other_mail@example.com", otherAuthor.Author)
+
+	time.Sleep(60 * time.Millisecond)
+
+	third, err := storage.Review().Create(ctx, model.TestReview(t))
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, third)
+
+	reviews, err := storage.Review().FindAll(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, reviews, 4)
+}
+
 func TestReviewRepository_FindOne(t *testing.T) {
 	store := testingstorage.New()
 
 	baseReview := model.TestReview(t)
 
-	id, err := store.Review().Create(baseReview)
+	id, err := store.Review().Create(context.Background(), baseReview)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -86,7 +129,7 @@ func TestReviewRepository_FindOne(t *testing.T) {
 
 	for _, testcase := range testTable {
 		t.Run(testcase.name, func(t *testing.T) {
-			returnedReview, err := store.Review().FindOne(testcase.inputId)
+			returnedReview, err := store.Review().FindOne(context.Background(), testcase.inputId)
 
 			if testcase.expectedReview != nil {
 				assert.NoError(t, err)
@@ -110,7 +153,7 @@ func TestReviewRepository_FindAll(t *testing.T) {
 		{
 			name: "empty table",
 			find: func() ([]model.Review, error) {
-				return store.Review().FindAll()
+				return store.Review().FindAll(context.Background())
 			},
 			expected: 0,
 		},
@@ -119,11 +162,11 @@ func TestReviewRepository_FindAll(t *testing.T) {
 			find: func() ([]model.Review, error) {
 				testingReview := model.TestReview(t)
 
-				store.Review().Create(testingReview)
-				store.Review().Create(testingReview)
-				store.Review().Create(testingReview)
+				store.Review().Create(context.Background(), testingReview)
+				store.Review().Create(context.Background(), testingReview)
+				store.Review().Create(context.Background(), testingReview)
 
-				return store.Review().FindAll()
+				return store.Review().FindAll(context.Background())
 			},
 			expected: 3,
 		},
@@ -150,7 +193,7 @@ func TestReviewRepository_Update(t *testing.T) {
 		Description: "review description",
 	}
 
-	id, err := store.Review().Create(baseReview)
+	id, err := store.Review().Create(context.Background(), baseReview)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -176,6 +219,7 @@ func TestReviewRepository_Update(t *testing.T) {
 				Rating:      3,
 				Title:       "review title",
 				Description: "review description",
+				Status:      model.StatusPending,
 			},
 		},
 		{
@@ -203,6 +247,7 @@ func TestReviewRepository_Update(t *testing.T) {
 				Rating:      4,
 				Title:       "review title",
 				Description: "review description",
+				Status:      model.StatusPending,
 			},
 		},
 		{
@@ -216,6 +261,7 @@ func TestReviewRepository_Update(t *testing.T) {
 				Rating:      4,
 				Title:       "review title",
 				Description: "review description",
+				Status:      model.StatusPending,
 			},
 		},
 		{
@@ -233,17 +279,21 @@ func TestReviewRepository_Update(t *testing.T) {
 
 	for _, testcase := range testTable {
 		t.Run(testcase.name, func(t *testing.T) {
-			err := store.Review().Update(testcase.inputReview)
+			err := store.Review().Update(context.Background(), testcase.inputReview)
 
 			if testcase.expectError {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
 
-				actualReview, err := store.Review().FindOne(int(id))
+				actualReview, err := store.Review().FindOne(context.Background(), int(id))
 				if err != nil {
 					t.Fatal(err)
 				}
+				assert.False(t, actualReview.UpdatedAt.Before(actualReview.CreatedAt))
+
+				testcase.expectedReview.CreatedAt = actualReview.CreatedAt
+				testcase.expectedReview.UpdatedAt = actualReview.UpdatedAt
 				assert.EqualValues(t, testcase.expectedReview, actualReview)
 			}
 		})
@@ -254,7 +304,7 @@ func TestReviewRepository_Delete(t *testing.T) {
 	store := testingstorage.New()
 
 	baseReview := model.TestReview(t)
-	id, err := store.Review().Create(baseReview)
+	id, err := store.Review().Create(context.Background(), baseReview)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -278,17 +328,496 @@ func TestReviewRepository_Delete(t *testing.T) {
 
 	for _, testcase := range testTable {
 		t.Run(testcase.name, func(t *testing.T) {
-			err := store.Review().Delete(testcase.inputId)
+			err := store.Review().Delete(context.Background(), testcase.inputId)
+
+			if testcase.expectError {
+				assert.Error(t, err)
+
+			} else {
+				assert.NoError(t, err)
+
+				_, err = store.Review().FindOne(context.Background(), testcase.inputId)
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestReviewRepository_FindPage(t *testing.T) {
+	store := testingstorage.New()
+
+	for _, review := range []*model.Review{
+		{Author: "first@example.com", Rating: 3, Title: "review title", Description: "review description", SubjectType: "product", SubjectID: "1"},
+		{Author: "second@example.com", Rating: 9, Title: "review title", Description: "review description", SubjectType: "product", SubjectID: "1"},
+		{Author: "first@example.com", Rating: 5, Title: "review title", Description: "review description"},
+		{Author: "third@example.com", Rating: 1, Title: "review title", Description: "review description"},
+	} {
+		if _, err := store.Review().Create(context.Background(), review); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testTable := []struct {
+		name           string
+		query          *model.ReviewQuery
+		expectedIDs    []int
+		expectedTotal  int
+		expectedCursor string
+		expectError    bool
+	}{
+		{
+			name:          "defaults",
+			query:         &model.ReviewQuery{},
+			expectedIDs:   []int{1, 2, 3, 4},
+			expectedTotal: 4,
+		},
+		{
+			name:           "first page",
+			query:          &model.ReviewQuery{Limit: 2},
+			expectedIDs:    []int{1, 2},
+			expectedTotal:  4,
+			expectedCursor: model.EncodeCursor(2),
+		},
+		{
+			name:          "next page by cursor",
+			query:         &model.ReviewQuery{Limit: 2, Cursor: model.EncodeCursor(2)},
+			expectedIDs:   []int{3, 4},
+			expectedTotal: 4,
+		},
+		{
+			name:          "sorted by rating desc",
+			query:         &model.ReviewQuery{SortBy: "rating", SortOrder: "desc"},
+			expectedIDs:   []int{2, 3, 1, 4},
+			expectedTotal: 4,
+		},
+		{
+			name:          "rating range",
+			query:         &model.ReviewQuery{MinRating: 3, MaxRating: 5},
+			expectedIDs:   []int{1, 3},
+			expectedTotal: 2,
+		},
+		{
+			name:          "author filter",
+			query:         &model.ReviewQuery{Author: "first@example.com", SortOrder: "desc"},
+			expectedIDs:   []int{3, 1},
+			expectedTotal: 2,
+		},
+		{
+			name:          "subject filter",
+			query:         &model.ReviewQuery{SubjectType: "product", SubjectID: "1"},
+			expectedIDs:   []int{1, 2},
+			expectedTotal: 2,
+		},
+		{
+			name:          "offset past the end",
+			query:         &model.ReviewQuery{Offset: 10},
+			expectedIDs:   []int{},
+			expectedTotal: 4,
+		},
+		{
+			name:        "invalid query",
+			query:       &model.ReviewQuery{SortBy: "unknown"},
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			page, err := store.Review().FindPage(context.Background(), testcase.query)
 
 			if testcase.expectError {
 				assert.Error(t, err)
+				assert.Nil(t, page)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			ids := make([]int, 0, len(page.Reviews))
+			for _, review := range page.Reviews {
+				ids = append(ids, review.ID)
+			}
+
+			assert.Equal(t, testcase.expectedIDs, ids)
+			assert.Equal(t, testcase.expectedTotal, page.Total)
+			assert.Equal(t, testcase.expectedCursor, page.NextCursor)
+		})
+	}
+}
+
+func TestReviewRepository_CountBySubject(t *testing.T) {
+	store := testingstorage.New()
 
+	for _, subjectID := range []string{"1", "1", "2"} {
+		review := model.TestReview(t)
+		review.SubjectType = "product"
+		review.SubjectID = subjectID
+		review.Status = model.StatusPublished
+
+		if _, err := store.Review().Create(context.Background(), review); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testTable := []struct {
+		name          string
+		inputSubject  *model.Subject
+		expectedCount int
+		expectError   bool
+	}{
+		{
+			name:          "two reviews",
+			inputSubject:  &model.Subject{Type: "product", ID: "1"},
+			expectedCount: 2,
+		},
+		{
+			name:          "no reviews",
+			inputSubject:  &model.Subject{Type: "hotel", ID: "1"},
+			expectedCount: 0,
+		},
+		{
+			name:         "missing id",
+			inputSubject: &model.Subject{Type: "product"},
+			expectError:  true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			count, err := store.Review().CountBySubject(context.Background(), testcase.inputSubject)
+
+			if testcase.expectError {
+				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, testcase.expectedCount, count)
+			}
+		})
+	}
+}
+
+func TestReviewRepository_FindRevisions(t *testing.T) {
+	store := testingstorage.New()
+
+	baseReview := model.TestReview(t)
+	id, err := store.Review().Create(context.Background(), baseReview)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edits := []*model.Review{
+		{ID: id, Title: "first edit", Aspects: map[string]int8{"delivery": 4}},
+		{ID: id, Title: "second edit", Aspects: map[string]int8{"delivery": 2}},
+	}
+	for _, edit := range edits {
+		if err := store.Review().Update(context.Background(), edit); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testTable := []struct {
+		name            string
+		inputId         int
+		expectedTitles  []string
+		expectedAspects []map[string]int8
+		expectError     bool
+	}{
+		{
+			name:            "valid",
+			inputId:         id,
+			expectedTitles:  []string{"Title", "first edit"},
+			expectedAspects: []map[string]int8{nil, {"delivery": 4}},
+		},
+		{
+			name:        "not existing review",
+			inputId:     id + 1,
+			expectError: true,
+		},
+		{
+			name:        "empty id",
+			inputId:     0,
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			revisions, err := store.Review().FindRevisions(context.Background(), testcase.inputId)
 
-				_, err = store.Review().FindOne(testcase.inputId)
+			if testcase.expectError {
 				assert.Error(t, err)
+				assert.Nil(t, revisions)
+				return
 			}
+
+			assert.NoError(t, err)
+
+			titles := make([]string, 0, len(revisions))
+			aspects := make([]map[string]int8, 0, len(revisions))
+			for _, revision := range revisions {
+				assert.Equal(t, testcase.inputId, revision.ReviewID)
+				assert.False(t, revision.RevisedAt.Before(revision.CreatedAt))
+				titles = append(titles, revision.Title)
+				aspects = append(aspects, revision.Aspects)
+			}
+			assert.Equal(t, testcase.expectedTitles, titles)
+			assert.Equal(t, testcase.expectedAspects, aspects)
 		})
 	}
 }
+
+func TestReviewRepository_Restore(t *testing.T) {
+	store := testingstorage.New()
+
+	baseReview := model.TestReview(t)
+	id, err := store.Review().Create(context.Background(), baseReview)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testTable := []struct {
+		name        string
+		prepare     func() error
+		inputId     int
+		expectError bool
+	}{
+		{
+			name: "deleted review",
+			prepare: func() error {
+				return store.Review().Delete(context.Background(), id)
+			},
+			inputId: id,
+		},
+		{
+			name: "not deleted review",
+			prepare: func() error {
+				return nil
+			},
+			inputId:     id,
+			expectError: true,
+		},
+		{
+			name: "empty id",
+			prepare: func() error {
+				return nil
+			},
+			inputId:     0,
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			if err := testcase.prepare(); err != nil {
+				t.Fatal(err)
+			}
+
+			err := store.Review().Restore(context.Background(), testcase.inputId)
+
+			if testcase.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+
+				review, err := store.Review().FindOne(context.Background(), testcase.inputId)
+				assert.NoError(t, err)
+				assert.Equal(t, baseReview, review)
+			}
+		})
+	}
+}
+
+func TestReviewRepository_Purge(t *testing.T) {
+	store := testingstorage.New()
+
+	ids := make([]int, 0, 3)
+	for range 3 {
+		id, err := store.Review().Create(context.Background(), model.TestReview(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := store.Review().Delete(context.Background(), ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Review().Delete(context.Background(), ids[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := store.Review().Purge(context.Background(), time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	count, err = store.Review().Purge(context.Background(), time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	assert.Error(t, store.Review().Restore(context.Background(), ids[0]))
+
+	reviews, err := store.Review().FindAll(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, reviews, 1)
+
+	id, err := store.Review().Create(context.Background(), model.TestReview(t))
+	assert.NoError(t, err)
+	assert.Greater(t, id, ids[2])
+}
+
+func TestReviewRepository_SetStatus(t *testing.T) {
+	store := testingstorage.New()
+
+	baseReview := model.TestReview(t)
+	id, err := store.Review().Create(context.Background(), baseReview)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, model.StatusPending, baseReview.Status)
+
+	testTable := []struct {
+		name        string
+		inputId     int
+		expectError bool
+	}{
+		{
+			name:    "valid",
+			inputId: id,
+		},
+		{
+			name:        "invalid transition",
+			inputId:     id,
+			expectError: true,
+		},
+		{
+			name:        "not existing review",
+			inputId:     id + 1,
+			expectError: true,
+		},
+		{
+			name:        "empty id",
+			inputId:     0,
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			moderation := &model.Moderation{
+				ReviewID:  testcase.inputId,
+				Moderator: "moderator@example.com",
+				Status:    model.StatusPublished,
+			}
+
+			err := store.Review().SetStatus(context.Background(), moderation)
+
+			if testcase.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.False(t, moderation.CreatedAt.IsZero())
+
+				review, err := store.Review().FindOne(context.Background(), testcase.inputId)
+				assert.NoError(t, err)
+				assert.Equal(t, model.StatusPublished, review.Status)
+			}
+		})
+	}
+}
+
+func TestReviewRepository_Vote(t *testing.T) {
+	store := testingstorage.New()
+
+	id, err := store.Review().Create(context.Background(), model.TestReview(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testTable := []struct {
+		name              string
+		inputVote         *model.Vote
+		expectedHelpful   int
+		expectedUnhelpful int
+		expectError       bool
+	}{
+		{
+			name:            "helpful",
+			inputVote:       model.TestVote(t, id, true),
+			expectedHelpful: 1,
+		},
+		{
+			name:            "same vote again",
+			inputVote:       model.TestVote(t, id, true),
+			expectedHelpful: 1,
+		},
+		{
+			name:              "changed vote",
+			inputVote:         model.TestVote(t, id, false),
+			expectedUnhelpful: 1,
+		},
+		{
+			name: "another voter",
+			inputVote: &model.Vote{
+				ReviewID: id,
+				Voter:    "another@example.com",
+				Helpful:  model.TestVote(t, id, true).Helpful,
+			},
+			expectedHelpful:   1,
+			expectedUnhelpful: 1,
+		},
+		{
+			name:              "not existing review",
+			inputVote:         model.TestVote(t, id+1, true),
+			expectedHelpful:   1,
+			expectedUnhelpful: 1,
+			expectError:       true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			err := store.Review().Vote(context.Background(), testcase.inputVote)
+
+			if testcase.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			review, err := store.Review().FindOne(context.Background(), id)
+			assert.NoError(t, err)
+			assert.Equal(t, testcase.expectedHelpful, review.HelpfulVotes)
+			assert.Equal(t, testcase.expectedUnhelpful, review.UnhelpfulVotes)
+		})
+	}
+}
+
+func TestReviewRepository_FindPage_Helpfulness(t *testing.T) {
+	store := testingstorage.New()
+
+	votes := [][2]int{{1, 0}, {20, 2}, {0, 3}, {6, 0}}
+	for _, count := range votes {
+		id, err := store.Review().Create(context.Background(), model.TestReview(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < count[0]+count[1]; i++ {
+			vote := model.TestVote(t, id, i < count[0])
+			vote.Voter = fmt.Sprintf("voter%d@example.com", i)
+
+			if err := store.Review().Vote(context.Background(), vote); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	page, err := store.Review().FindPage(context.Background(), &model.ReviewQuery{SortBy: "helpfulness", SortOrder: "desc"})
+	assert.NoError(t, err)
+
+	actualIDs := make([]int, 0, len(page.Reviews))
+	for _, review := range page.Reviews {
+		actualIDs = append(actualIDs, review.ID)
+	}
+	assert.Equal(t, []int{2, 4, 1, 3}, actualIDs)
+}
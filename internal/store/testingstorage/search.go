@@ -0,0 +1,79 @@
+package testingstorage
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+)
+
+const snippetWords = 20
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func matchesTerm(word string, terms []string) bool {
+	for _, token := range tokenize(word) {
+		for _, term := range terms {
+			if strings.HasPrefix(token, term) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func countMatches(text string, term string) int {
+	count := 0
+	for _, token := range tokenize(text) {
+		if strings.HasPrefix(token, term) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func searchRank(review *model.Review, terms []string) float64 {
+	var rank float64
+
+	for _, term := range terms {
+		title, description := countMatches(review.Title, term), countMatches(review.Description, term)
+		if title == 0 && description == 0 {
+			return 0
+		}
+
+		rank += float64(2*title + description)
+	}
+
+	return rank / float64(len(tokenize(review.Title))+len(tokenize(review.Description)))
+}
+
+func highlight(text string, terms []string) string {
+	words := strings.Fields(text)
+
+	start := 0
+	for i, word := range words {
+		if matchesTerm(word, terms) {
+			start = max(i-snippetWords/4, 0)
+			break
+		}
+	}
+	end := min(start+snippetWords, len(words))
+
+	snippet := make([]string, 0, end-start)
+	for _, word := range words[start:end] {
+		if matchesTerm(word, terms) {
+			snippet = append(snippet, model.HighlightStart+html.EscapeString(word)+model.HighlightStop)
+			continue
+		}
+		snippet = append(snippet, html.EscapeString(word))
+	}
+
+	return strings.Join(snippet, " ")
+}
@@ -0,0 +1,101 @@
+package testingstorage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Restyx/golang-reviews-service/internal/model"
+	"github.com/Restyx/golang-reviews-service/internal/store/testingstorage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewRepository_Search(t *testing.T) {
+	store := testingstorage.New()
+
+	reviews := []*model.Review{
+		{Author: "first@example.com", Rating: 9, Title: "Great battery", Description: "The battery lasts two days with heavy use"},
+		{Author: "second@example.com", Rating: 4, Title: "Average phone", Description: "Screen is fine, battery could be better"},
+		{Author: "third@example.com", Rating: 7, Title: "Nice screen", Description: "Bright and sharp display"},
+	}
+	for _, review := range reviews {
+		if _, err := store.Review().Create(context.Background(), review); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testTable := []struct {
+		name           string
+		inputQuery     *model.SearchQuery
+		expectedIDs    []int
+		expectedCursor string
+		expectError    bool
+	}{
+		{
+			name:        "ranked by relevance",
+			inputQuery:  &model.SearchQuery{Query: "Battery"},
+			expectedIDs: []int{reviews[0].ID, reviews[1].ID},
+		},
+		{
+			name:        "all terms must match",
+			inputQuery:  &model.SearchQuery{Query: "battery screen"},
+			expectedIDs: []int{reviews[1].ID},
+		},
+		{
+			name:        "prefix match",
+			inputQuery:  &model.SearchQuery{Query: "displ"},
+			expectedIDs: []int{reviews[2].ID},
+		},
+		{
+			name:           "paginated",
+			inputQuery:     &model.SearchQuery{Query: "battery", Limit: 1},
+			expectedIDs:    []int{reviews[0].ID},
+			expectedCursor: model.EncodeCursor(1),
+		},
+		{
+			name:        "no match",
+			inputQuery:  &model.SearchQuery{Query: "keyboard"},
+			expectedIDs: []int{},
+		},
+		{
+			name:        "empty query",
+			inputQuery:  &model.SearchQuery{},
+			expectError: true,
+		},
+	}
+
+	for _, testcase := range testTable {
+		t.Run(testcase.name, func(t *testing.T) {
+			page, err := store.Review().Search(context.Background(), testcase.inputQuery)
+
+			if testcase.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, page)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			actualIDs := make([]int, 0, len(page.Results))
+			for _, result := range page.Results {
+				actualIDs = append(actualIDs, result.ID)
+				assert.Greater(t, result.Rank, 0.0)
+				assert.Contains(t, result.Snippet, model.HighlightStart)
+			}
+			assert.Equal(t, testcase.expectedIDs, actualIDs)
+			assert.Equal(t, testcase.expectedCursor, page.NextCursor)
+		})
+	}
+
+	page, err := store.Review().Search(context.Background(), &model.SearchQuery{Query: "lasts"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Great battery The battery <mark>lasts</mark> two days with heavy use", page.Results[0].Snippet)
+
+	markup := &model.Review{Author: "fourth@example.com", Rating: 2, Title: "Broken <b>charger</b>", Description: `<script>alert("x")</script>`}
+	if _, err := store.Review().Create(context.Background(), markup); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err = store.Review().Search(context.Background(), &model.SearchQuery{Query: "broken"})
+	assert.NoError(t, err)
+	assert.Equal(t, "<mark>Broken</mark> &lt;b&gt;charger&lt;/b&gt; &lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;", page.Results[0].Snippet)
+}
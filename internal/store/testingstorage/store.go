@@ -1,25 +1,83 @@
 package testingstorage
 
 import (
+	"time"
+
 	"github.com/Restyx/golang-reviews-service/internal/model"
 	"github.com/Restyx/golang-reviews-service/internal/store"
 )
 
 type Store struct {
 	reviewRepository *ReviewRepository
+	reportRepository *ReportRepository
+	replyRepository  *ReplyRepository
+	outboxRepository *OutboxRepository
+	outbox           bool
+	idempotency      time.Duration
 }
 
 func New() *Store {
 	return &Store{}
 }
 
+func (s *Store) EnableOutbox() {
+	s.outbox = true
+}
+
+func (s *Store) EnableIdempotency(window time.Duration) {
+	s.idempotency = window
+}
+
 func (s *Store) Review() store.ReviewRepositoryI {
 	if s.reviewRepository == nil {
 		s.reviewRepository = &ReviewRepository{
-			store:   s,
-			reviews: make(map[int]*model.Review),
+			store:       s,
+			reviews:     make(map[int]*model.Review),
+			revisions:   make(map[int][]model.ReviewRevision),
+			deleted:     make(map[int]time.Time),
+			moderations: make(map[int][]model.Moderation),
+			votes:       make(map[int]map[string]bool),
+			keys:        make(map[idempotencyScope]idempotencyKey),
 		}
 	}
 
 	return s.reviewRepository
 }
+
+func (s *Store) Report() store.ReportRepositoryI {
+	if s.reportRepository == nil {
+		s.reportRepository = &ReportRepository{
+			store:   s,
+			reports: make(map[int][]*model.Report),
+		}
+	}
+
+	return s.reportRepository
+}
+
+func (s *Store) Reply() store.ReplyRepositoryI {
+	if s.replyRepository == nil {
+		s.replyRepository = &ReplyRepository{
+			store:   s,
+			replies: make(map[int]*model.Reply),
+		}
+	}
+
+	return s.replyRepository
+}
+
+func (s *Store) Outbox() store.OutboxRepositoryI {
+	if s.outboxRepository == nil {
+		s.outboxRepository = &OutboxRepository{
+			store: s,
+		}
+	}
+
+	return s.outboxRepository
+}
+
+func (s *Store) recordEvent(eventType string, before, after *model.Review) {
+	if s.outbox {
+		s.Outbox().(*OutboxRepository).record(eventType, before, after)
+	}
+}
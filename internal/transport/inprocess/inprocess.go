@@ -0,0 +1,286 @@
+package inprocess
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Restyx/golang-reviews-service/internal/transport"
+)
+
+const replyQueue = "inprocess-reply"
+
+var (
+	ErrUnroutable = errors.New("no consumer bound to pattern")
+	ErrClosed     = errors.New("transport is closed")
+	ErrRejected   = errors.New("message rejected")
+	ErrNoReply    = errors.New("message was not replied to")
+)
+
+type Option func(*message)
+
+func WithHeader(key string, value interface{}) Option {
+	return func(m *message) {
+		m.headers[key] = value
+	}
+}
+
+func WithMessageID(id string) Option {
+	return func(m *message) {
+		m.messageID = id
+	}
+}
+
+func WithExpiration(ttl time.Duration) Option {
+	return func(m *message) {
+		m.deadline = time.Now().Add(ttl)
+	}
+}
+
+type Transport struct {
+	mu         sync.RWMutex
+	bindingsMu sync.RWMutex
+	bindings   map[string]bool
+	messages   chan transport.Message
+	stop       chan struct{}
+	stopOnce   sync.Once
+	lastID     atomic.Int64
+	maxRetries int
+	closed     bool
+}
+
+func New() *Transport {
+	return &Transport{
+		bindings: make(map[string]bool),
+		messages: make(chan transport.Message),
+		stop:     make(chan struct{}),
+	}
+}
+
+func (t *Transport) SetMaxRetries(maxRetries int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.maxRetries = maxRetries
+}
+
+func (t *Transport) Consume(patterns []string) (<-chan transport.Message, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.closed {
+		return nil, ErrClosed
+	}
+
+	t.bindingsMu.Lock()
+	defer t.bindingsMu.Unlock()
+
+	for _, pattern := range patterns {
+		t.bindings[pattern] = true
+	}
+
+	return t.messages, nil
+}
+
+func (t *Transport) Reply(msg transport.Message, reply *transport.Reply) error {
+	m, ok := msg.(*message)
+	if !ok {
+		return fmt.Errorf("inprocess: cannot reply to %T", msg)
+	}
+
+	if m.replyTo == "" {
+		return ErrNoReply
+	}
+
+	select {
+	case m.reply <- reply:
+		return nil
+	default:
+		return fmt.Errorf("inprocess: message %s already replied to", m.correlationID)
+	}
+}
+
+func (t *Transport) Publish(pattern string, body []byte, options ...Option) error {
+	msg, err := t.send(pattern, body, "", options)
+	if err != nil {
+		return err
+	}
+
+	if !<-msg.done {
+		return ErrRejected
+	}
+
+	return nil
+}
+
+func (t *Transport) Request(pattern string, body []byte, options ...Option) (*transport.Reply, error) {
+	msg, err := t.send(pattern, body, replyQueue, options)
+	if err != nil {
+		return nil, err
+	}
+
+	<-msg.done
+
+	select {
+	case reply := <-msg.reply:
+		return reply, nil
+	default:
+		return nil, ErrNoReply
+	}
+}
+
+func (t *Transport) Cancel() error {
+	t.Close()
+
+	return nil
+}
+
+func (t *Transport) Close() {
+	t.stopOnce.Do(func() {
+		close(t.stop)
+	})
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.closed {
+		t.closed = true
+		close(t.messages)
+	}
+}
+
+func (t *Transport) retry(msg *message) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.closed {
+		return ErrClosed
+	}
+
+	retries, _ := msg.headers[transport.RetryCountHeader].(int)
+	if retries >= t.maxRetries {
+		return transport.ErrRetriesExhausted
+	}
+	msg.headers[transport.RetryCountHeader] = retries + 1
+
+	go func() {
+		t.mu.RLock()
+		defer t.mu.RUnlock()
+
+		if t.closed {
+			msg.settle(false)
+			return
+		}
+
+		select {
+		case t.messages <- msg:
+		case <-t.stop:
+			msg.settle(false)
+		}
+	}()
+
+	return nil
+}
+
+func (t *Transport) send(pattern string, body []byte, replyTo string, options []Option) (*message, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.closed {
+		return nil, ErrClosed
+	}
+
+	t.bindingsMu.RLock()
+	bound := t.bindings[pattern]
+	t.bindingsMu.RUnlock()
+
+	if !bound {
+		return nil, fmt.Errorf("%w: %s", ErrUnroutable, pattern)
+	}
+
+	msg := &message{
+		transport:     t,
+		pattern:       pattern,
+		body:          body,
+		replyTo:       replyTo,
+		correlationID: fmt.Sprint(t.lastID.Add(1)),
+		headers:       make(map[string]interface{}),
+		reply:         make(chan *transport.Reply, 1),
+		done:          make(chan bool, 1),
+	}
+
+	for _, option := range options {
+		option(msg)
+	}
+
+	select {
+	case t.messages <- msg:
+		return msg, nil
+	case <-t.stop:
+		return nil, ErrClosed
+	}
+}
+
+type message struct {
+	transport     *Transport
+	pattern       string
+	body          []byte
+	replyTo       string
+	correlationID string
+	messageID     string
+	headers       map[string]interface{}
+	deadline      time.Time
+	reply         chan *transport.Reply
+	done          chan bool
+	once          sync.Once
+}
+
+func (m *message) Pattern() string {
+	return m.pattern
+}
+
+func (m *message) Body() []byte {
+	return m.body
+}
+
+func (m *message) ReplyTo() string {
+	return m.replyTo
+}
+
+func (m *message) CorrelationID() string {
+	return m.correlationID
+}
+
+func (m *message) MessageID() string {
+	return m.messageID
+}
+
+func (m *message) Headers() map[string]interface{} {
+	return m.headers
+}
+
+func (m *message) Deadline() (time.Time, bool) {
+	return m.deadline, !m.deadline.IsZero()
+}
+
+func (m *message) Ack() error {
+	m.settle(true)
+	return nil
+}
+
+func (m *message) Nack() error {
+	m.settle(false)
+	return nil
+}
+
+func (m *message) Retry() error {
+	return m.transport.retry(m)
+}
+
+func (m *message) settle(acked bool) {
+	m.once.Do(func() {
+		m.done <- acked
+	})
+}
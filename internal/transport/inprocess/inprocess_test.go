@@ -0,0 +1,98 @@
+package inprocess_test
+
+import (
+	"testing"
+
+	"github.com/Restyx/golang-reviews-service/internal/transport"
+	"github.com/Restyx/golang-reviews-service/internal/transport/inprocess"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransport_Publish(t *testing.T) {
+	bus := inprocess.New()
+	defer bus.Close()
+
+	messages, err := bus.Consume([]string{"ack", "nack"})
+	assert.NoError(t, err)
+
+	go func() {
+		for msg := range messages {
+			if msg.Pattern() == "ack" {
+				msg.Ack()
+			} else {
+				msg.Nack()
+			}
+		}
+	}()
+
+	assert.NoError(t, bus.Publish("ack", []byte("{}")))
+	assert.ErrorIs(t, bus.Publish("nack", []byte("{}")), inprocess.ErrRejected)
+	assert.ErrorIs(t, bus.Publish("unbound", []byte("{}")), inprocess.ErrUnroutable)
+}
+
+func TestTransport_Request(t *testing.T) {
+	bus := inprocess.New()
+	defer bus.Close()
+
+	messages, err := bus.Consume([]string{"echo", "silent"})
+	assert.NoError(t, err)
+
+	go func() {
+		for msg := range messages {
+			if msg.Pattern() == "echo" {
+				assert.NotEmpty(t, msg.ReplyTo())
+				assert.NotEmpty(t, msg.CorrelationID())
+				assert.NoError(t, bus.Reply(msg, &transport.Reply{Code: 200, Body: msg.Body()}))
+			}
+			msg.Ack()
+		}
+	}()
+
+	reply, err := bus.Request("echo", []byte(`{"id":1}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(200), reply.Code)
+	assert.Equal(t, []byte(`{"id":1}`), reply.Body)
+
+	_, err = bus.Request("silent", []byte("{}"))
+	assert.ErrorIs(t, err, inprocess.ErrNoReply)
+}
+
+func TestTransport_Close(t *testing.T) {
+	bus := inprocess.New()
+
+	messages, err := bus.Consume([]string{"pattern"})
+	assert.NoError(t, err)
+
+	bus.Close()
+
+	_, ok := <-messages
+	assert.False(t, ok)
+	assert.ErrorIs(t, bus.Publish("pattern", nil), inprocess.ErrClosed)
+}
+
+func TestTransport_Retry(t *testing.T) {
+	bus := inprocess.New()
+	bus.SetMaxRetries(2)
+	defer bus.Close()
+
+	messages, err := bus.Consume([]string{"flaky", "broken"})
+	assert.NoError(t, err)
+
+	go func() {
+		for msg := range messages {
+			retries, _ := msg.Headers()[transport.RetryCountHeader].(int)
+			if msg.Pattern() == "flaky" && retries == 2 {
+				msg.Ack()
+				continue
+			}
+
+			if err := msg.Retry(); err != nil {
+				assert.ErrorIs(t, err, transport.ErrRetriesExhausted)
+				msg.Nack()
+			}
+		}
+	}()
+
+	assert.NoError(t, bus.Publish("flaky", nil))
+	assert.ErrorIs(t, bus.Publish("broken", nil), inprocess.ErrRejected)
+}
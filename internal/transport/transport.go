@@ -0,0 +1,48 @@
+package transport
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	RetryCountHeader     = "x-retry-count"
+	IdempotencyKeyHeader = "idempotency-key"
+)
+
+var (
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrRetriesExhausted = errors.New("retries exhausted")
+)
+
+type Message interface {
+	Pattern() string
+	Body() []byte
+	ReplyTo() string
+	CorrelationID() string
+	MessageID() string
+	Headers() map[string]interface{}
+	Deadline() (time.Time, bool)
+	Ack() error
+	Nack() error
+	Retry() error
+}
+
+type Reply struct {
+	Code int32
+	Body []byte
+}
+
+type Replier interface {
+	Reply(Message, *Reply) error
+}
+
+type Consumer interface {
+	Consume(patterns []string) (<-chan Message, error)
+	Cancel() error
+}
+
+type Transport interface {
+	Consumer
+	Replier
+}
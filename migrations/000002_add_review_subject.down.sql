@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS reviews_subject_idx;
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS subject_type,
+    DROP COLUMN IF EXISTS subject_id;
@@ -0,0 +1,5 @@
+ALTER TABLE reviews
+    ADD COLUMN IF NOT EXISTS subject_type VARCHAR (50) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS subject_id VARCHAR (100) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS reviews_subject_idx ON reviews (subject_type, subject_id);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS reviews_stats ON reviews;
+DROP FUNCTION IF EXISTS review_stats_trigger();
+DROP FUNCTION IF EXISTS review_stats_apply(VARCHAR, VARCHAR, smallint, integer);
+DROP TABLE IF EXISTS review_stats;
@@ -0,0 +1,55 @@
+CREATE TABLE IF NOT EXISTS review_stats(
+    subject_type VARCHAR (50) NOT NULL,
+    subject_id VARCHAR (100) NOT NULL,
+    rating_count integer NOT NULL DEFAULT 0,
+    rating_sum bigint NOT NULL DEFAULT 0,
+    histogram integer[] NOT NULL DEFAULT array_fill(0, ARRAY[10]),
+    PRIMARY KEY (subject_type, subject_id)
+);
+
+CREATE OR REPLACE FUNCTION review_stats_apply(p_type VARCHAR, p_id VARCHAR, p_rating smallint, p_delta integer) RETURNS void AS $$
+BEGIN
+    INSERT INTO review_stats (subject_type, subject_id) VALUES (p_type, p_id) ON CONFLICT DO NOTHING;
+
+    UPDATE review_stats SET
+        rating_count = rating_count + p_delta,
+        rating_sum = rating_sum + p_delta * p_rating,
+        histogram[p_rating] = histogram[p_rating] + p_delta
+    WHERE subject_type = p_type AND subject_id = p_id;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION review_stats_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM review_stats_apply(OLD.subject_type, OLD.subject_id, OLD.rating, -1);
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM review_stats_apply(NEW.subject_type, NEW.subject_id, NEW.rating, 1);
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER reviews_stats
+AFTER INSERT OR DELETE OR UPDATE OF rating, subject_type, subject_id ON reviews
+FOR EACH ROW EXECUTE FUNCTION review_stats_trigger();
+
+INSERT INTO review_stats (subject_type, subject_id, rating_count, rating_sum, histogram)
+SELECT subject_type, subject_id, COUNT(*), SUM(rating), ARRAY[
+    COUNT(*) FILTER (WHERE rating = 1),
+    COUNT(*) FILTER (WHERE rating = 2),
+    COUNT(*) FILTER (WHERE rating = 3),
+    COUNT(*) FILTER (WHERE rating = 4),
+    COUNT(*) FILTER (WHERE rating = 5),
+    COUNT(*) FILTER (WHERE rating = 6),
+    COUNT(*) FILTER (WHERE rating = 7),
+    COUNT(*) FILTER (WHERE rating = 8),
+    COUNT(*) FILTER (WHERE rating = 9),
+    COUNT(*) FILTER (WHERE rating = 10)
+]::integer[]
+FROM reviews
+GROUP BY subject_type, subject_id
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS review_revisions;
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,16 @@
+ALTER TABLE reviews
+    ADD COLUMN IF NOT EXISTS created_at timestamptz NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS updated_at timestamptz NOT NULL DEFAULT now();
+
+CREATE TABLE IF NOT EXISTS review_revisions(
+    id serial PRIMARY KEY,
+    review_id integer NOT NULL REFERENCES reviews (id) ON DELETE CASCADE,
+    author VARCHAR (50) NOT NULL,
+    rating smallint NOT NULL,
+    title VARCHAR (50),
+    description TEXT,
+    created_at timestamptz NOT NULL,
+    revised_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS review_revisions_review_idx ON review_revisions (review_id, id);
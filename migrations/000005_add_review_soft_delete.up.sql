@@ -0,0 +1,27 @@
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS reviews_deleted_at_idx ON reviews (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION review_stats_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        IF OLD.deleted_at IS NULL THEN
+            PERFORM review_stats_apply(OLD.subject_type, OLD.subject_id, OLD.rating, -1);
+        END IF;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        IF NEW.deleted_at IS NULL THEN
+            PERFORM review_stats_apply(NEW.subject_type, NEW.subject_id, NEW.rating, 1);
+        END IF;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS reviews_stats ON reviews;
+
+CREATE TRIGGER reviews_stats
+AFTER INSERT OR DELETE OR UPDATE OF rating, subject_type, subject_id, deleted_at ON reviews
+FOR EACH ROW EXECUTE FUNCTION review_stats_trigger();
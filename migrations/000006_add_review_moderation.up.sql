@@ -0,0 +1,39 @@
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS status VARCHAR (20) NOT NULL DEFAULT 'published';
+ALTER TABLE reviews ALTER COLUMN status SET DEFAULT 'pending';
+
+CREATE INDEX IF NOT EXISTS reviews_status_idx ON reviews (status);
+
+CREATE TABLE IF NOT EXISTS review_moderations(
+    id serial PRIMARY KEY,
+    review_id integer NOT NULL REFERENCES reviews (id) ON DELETE CASCADE,
+    status VARCHAR (20) NOT NULL,
+    moderator VARCHAR (100) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS review_moderations_review_idx ON review_moderations (review_id, id);
+
+CREATE OR REPLACE FUNCTION review_stats_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        IF OLD.deleted_at IS NULL AND OLD.status = 'published' THEN
+            PERFORM review_stats_apply(OLD.subject_type, OLD.subject_id, OLD.rating, -1);
+        END IF;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        IF NEW.deleted_at IS NULL AND NEW.status = 'published' THEN
+            PERFORM review_stats_apply(NEW.subject_type, NEW.subject_id, NEW.rating, 1);
+        END IF;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS reviews_stats ON reviews;
+
+CREATE TRIGGER reviews_stats
+AFTER INSERT OR DELETE OR UPDATE OF rating, subject_type, subject_id, deleted_at, status ON reviews
+FOR EACH ROW EXECUTE FUNCTION review_stats_trigger();
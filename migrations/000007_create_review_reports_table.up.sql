@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS review_reports(
+    id serial PRIMARY KEY,
+    review_id integer NOT NULL REFERENCES reviews (id) ON DELETE CASCADE,
+    reporter VARCHAR (100) NOT NULL,
+    reason VARCHAR (20) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (review_id, reporter)
+);
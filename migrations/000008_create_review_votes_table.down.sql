@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS review_votes;
+DROP FUNCTION IF EXISTS review_votes_trigger();
+DROP INDEX IF EXISTS reviews_helpfulness_idx;
+DROP FUNCTION IF EXISTS review_helpfulness(integer, integer);
+
+ALTER TABLE reviews DROP COLUMN IF EXISTS unhelpful_votes;
+ALTER TABLE reviews DROP COLUMN IF EXISTS helpful_votes;
@@ -0,0 +1,46 @@
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS helpful_votes integer NOT NULL DEFAULT 0;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS unhelpful_votes integer NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS review_votes(
+    review_id integer NOT NULL REFERENCES reviews (id) ON DELETE CASCADE,
+    voter VARCHAR (100) NOT NULL,
+    helpful boolean NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (review_id, voter)
+);
+
+CREATE OR REPLACE FUNCTION review_helpfulness(positive integer, negative integer) RETURNS float8 AS $$
+    SELECT CASE WHEN positive + negative = 0 THEN 0 ELSE
+        ((positive + 1.9208) / (positive + negative)
+            - 1.96 * sqrt(positive::float8 * negative / (positive + negative) + 0.9604) / (positive + negative))
+        / (1 + 3.8416 / (positive + negative))
+    END::float8;
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE INDEX IF NOT EXISTS reviews_helpfulness_idx ON reviews (review_helpfulness(helpful_votes, unhelpful_votes), id);
+
+CREATE OR REPLACE FUNCTION review_votes_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE reviews SET
+            helpful_votes = helpful_votes - CASE WHEN OLD.helpful THEN 1 ELSE 0 END,
+            unhelpful_votes = unhelpful_votes - CASE WHEN OLD.helpful THEN 0 ELSE 1 END
+        WHERE id = OLD.review_id;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE reviews SET
+            helpful_votes = helpful_votes + CASE WHEN NEW.helpful THEN 1 ELSE 0 END,
+            unhelpful_votes = unhelpful_votes + CASE WHEN NEW.helpful THEN 0 ELSE 1 END
+        WHERE id = NEW.review_id;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS review_votes_counts ON review_votes;
+
+CREATE TRIGGER review_votes_counts
+AFTER INSERT OR DELETE OR UPDATE OF helpful ON review_votes
+FOR EACH ROW EXECUTE FUNCTION review_votes_trigger();
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS review_replies(
+    id serial PRIMARY KEY,
+    review_id integer NOT NULL REFERENCES reviews (id) ON DELETE CASCADE,
+    parent_id integer REFERENCES review_replies (id) ON DELETE CASCADE,
+    author VARCHAR (100) NOT NULL,
+    role VARCHAR (20) NOT NULL,
+    body TEXT NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS review_replies_review_idx ON review_replies (review_id, id);
+CREATE INDEX IF NOT EXISTS review_replies_role_idx ON review_replies (review_id, role, created_at DESC);
@@ -0,0 +1 @@
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS aspects jsonb NOT NULL DEFAULT '{}';
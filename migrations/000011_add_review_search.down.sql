@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS reviews_search_idx;
+DROP TRIGGER IF EXISTS reviews_search_vector ON reviews;
+DROP FUNCTION IF EXISTS review_search_vector_trigger();
+
+ALTER TABLE reviews DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE reviews DROP COLUMN IF EXISTS search_language;
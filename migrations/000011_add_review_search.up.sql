@@ -0,0 +1,24 @@
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS search_language regconfig NOT NULL DEFAULT 'english';
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION review_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector(NEW.search_language, COALESCE(NEW.title, '')), 'A') ||
+        setweight(to_tsvector(NEW.search_language, COALESCE(NEW.description, '')), 'B');
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS reviews_search_vector ON reviews;
+
+CREATE TRIGGER reviews_search_vector
+BEFORE INSERT OR UPDATE OF title, description, search_language ON reviews
+FOR EACH ROW EXECUTE FUNCTION review_search_vector_trigger();
+
+UPDATE reviews SET search_vector =
+    setweight(to_tsvector(search_language, COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector(search_language, COALESCE(description, '')), 'B');
+
+CREATE INDEX IF NOT EXISTS reviews_search_idx ON reviews USING GIN (search_vector);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS review_outbox(
+    id bigserial PRIMARY KEY,
+    event_id VARCHAR (64) NOT NULL UNIQUE,
+    event_type VARCHAR (50) NOT NULL,
+    review_id integer NOT NULL,
+    payload jsonb NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    sent_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS review_outbox_pending_idx ON review_outbox (id) WHERE sent_at IS NULL;
+CREATE INDEX IF NOT EXISTS review_outbox_sent_idx ON review_outbox (sent_at) WHERE sent_at IS NOT NULL;
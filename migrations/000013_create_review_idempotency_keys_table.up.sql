@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS review_idempotency_keys(
+    author VARCHAR (255) NOT NULL,
+    key VARCHAR (255) NOT NULL,
+    review_id integer NOT NULL REFERENCES reviews (id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    expires_at timestamptz NOT NULL,
+    PRIMARY KEY (author, key)
+);
+
+CREATE INDEX IF NOT EXISTS review_idempotency_keys_expires_idx ON review_idempotency_keys (expires_at);
@@ -0,0 +1 @@
+ALTER TABLE review_revisions ADD COLUMN IF NOT EXISTS aspects jsonb NOT NULL DEFAULT '{}';